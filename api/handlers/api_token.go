@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type APITokenHandler struct {
+	apiTokenService *service.APITokenService
+}
+
+func NewAPITokenHandler(apiTokenService *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{apiTokenService: apiTokenService}
+}
+
+// GetTokens godoc
+// @Summary List API tokens
+// @Description List the personal API tokens of the current user with their scopes, expiry and last use. The tokens themselves are not shown. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string][]models.APIToken "API tokens"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Called with an API token"
+// @Router /auth/tokens [get]
+func (h *APITokenHandler) GetTokens(c *gin.Context) {
+	tokens, err := h.apiTokenService.GetTokens(c.GetInt("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"apiTokens": tokens})
+}
+
+// CreateToken godoc
+// @Summary Create API token
+// @Description Create a personal API token for automation such as CI pipelines. It acts as the current user, limited to its scopes, until it expires or is revoked. The token is shown only in this response. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.CreateAPITokenRequest true "Token name, scopes and optional expiry"
+// @Success 201 {object} models.CreateAPITokenResponse "API token created"
+// @Failure 400 {object} models.Problem "Validation error"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Called with an API token"
+// @Router /auth/tokens [post]
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.apiTokenService.CreateToken(c.GetInt("user_id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RevokeToken godoc
+// @Summary Revoke API token
+// @Description Delete one of the current user's API tokens; it stops working immediately. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "API token ID"
+// @Success 200 {object} map[string]string "API token revoked"
+// @Failure 400 {object} models.Problem "Invalid API token ID"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Called with an API token"
+// @Failure 404 {object} models.Problem "API token not found"
+// @Router /auth/tokens/{id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid API token ID")
+		return
+	}
+
+	err = h.apiTokenService.RevokeToken(c.GetInt("user_id"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
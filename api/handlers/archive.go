@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/archive"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+// maxImportUpload caps the size of an uploaded import archive.
+const maxImportUpload = 128 << 20
+
+type ArchiveHandler struct {
+	archiveService *service.ArchiveService
+}
+
+func NewArchiveHandler(archiveService *service.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{archiveService: archiveService}
+}
+
+// ExportWebsite godoc
+// @Summary Export website archive
+// @Description Download a website as a tar.gz or zip archive: website.json with the website metadata (without secrets), pages/<slug>.md with YAML front matter, and the page assets under assets/
+// @Tags Websites
+// @Produce application/gzip
+// @Produce application/zip
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Param format query string false "Archive format: tar.gz (default) or zip"
+// @Success 200 {file} file "Website archive"
+// @Failure 400 {object} models.Problem "Invalid website ID or format"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Router /websites/{id}/export [get]
+func (h *ArchiveHandler) ExportWebsite(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	format := c.DefaultQuery("format", archive.FormatTarGz)
+	if format != archive.FormatTarGz && format != archive.FormatZip {
+		utils.InvalidParameterResponse(c, "Invalid format parameter")
+		return
+	}
+
+	data, filename, err := h.archiveService.ExportWebsite(id, format)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, archive.ContentType(format), data)
+}
+
+// ImportWebsite godoc
+// @Summary Import website archive
+// @Description Recreate a website with its pages and assets from an export archive. onConflict decides what happens when the website or page slugs already exist: fail (default) rejects the import with 409, rename imports under free slugs and rewrites internal links, overwrite replaces the website with the same slug and its pages of the same slug.
+// @Tags Websites
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param archive formData file true "tar.gz or zip archive produced by the export endpoint"
+// @Param onConflict formData string false "Conflict strategy: fail, rename or overwrite"
+// @Param gitApiToken formData string false "Git API token for the website, which is not part of the archive"
+// @Success 201 {object} map[string]models.ImportReport "Import report"
+// @Failure 400 {object} models.Problem "Invalid archive or request"
+// @Failure 409 {object} models.Problem "Slugs or names already exist"
+// @Router /websites/import [post]
+func (h *ArchiveHandler) ImportWebsite(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportUpload)
+
+	var req models.ImportWebsiteRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		utils.BadRequestResponse(c, "archive file is required")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	report, err := h.archiveService.ImportWebsite(data, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"import": report})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+func init() {
+	// Report binding errors with the names clients send instead of the Go
+	// field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+// respondError maps a service error to its status code and writes it as a
+// problem details response. Unexpected errors become a 500 without details.
+func respondError(c *gin.Context, err error) {
+	var (
+		validationErr   *service.ValidationError
+		lintErr         *service.LintError
+		importErr       *service.ImportConflictError
+		conflictErr     *service.ConflictError
+		forbiddenErr    *service.ForbiddenError
+		preconditionErr *service.PreconditionFailedError
+		throttledErr    *service.ThrottledError
+		ssoErr          *service.SSOError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		var members gin.H
+		if len(validationErr.Fields) > 0 {
+			members = gin.H{"errors": validationErr.Fields}
+		}
+		utils.ProblemResponse(c, http.StatusBadRequest, service.CodeValidationFailed, err.Error(), members)
+	case errors.As(err, &lintErr):
+		utils.ProblemResponse(c, http.StatusUnprocessableEntity, service.CodeLintFailed, err.Error(), gin.H{"lint": lintErr.Result})
+	case errors.As(err, &importErr):
+		utils.ProblemResponse(c, http.StatusConflict, service.CodeImportConflict, err.Error(), gin.H{"conflicts": importErr.Conflicts})
+	case errors.As(err, &conflictErr):
+		code := conflictErr.Code
+		if code == "" {
+			code = service.CodeConflict
+		}
+		utils.ProblemResponse(c, http.StatusConflict, code, err.Error(), nil)
+	case errors.As(err, &forbiddenErr):
+		utils.ProblemResponse(c, http.StatusForbidden, service.CodeForbidden, err.Error(), nil)
+	case errors.As(err, &preconditionErr):
+		utils.ProblemResponse(c, http.StatusPreconditionFailed, service.CodePreconditionFailed, err.Error(),
+			gin.H{"currentETag": etag(preconditionErr.CurrentVersion)})
+	case errors.As(err, &throttledErr):
+		retryAfter := int(math.Ceil(throttledErr.RetryAfter.Seconds()))
+		code := service.CodeLoginThrottled
+		if throttledErr.Locked {
+			code = service.CodeAccountLocked
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		utils.ProblemResponse(c, http.StatusTooManyRequests, code, err.Error(), gin.H{"retryAfter": retryAfter})
+	case errors.As(err, &ssoErr):
+		utils.ProblemResponse(c, http.StatusUnauthorized, service.CodeSSOFailed, err.Error(), nil)
+	case errors.Is(err, service.ErrVersionConflict):
+		utils.ProblemResponse(c, http.StatusConflict, service.CodeEditConflict, err.Error(), nil)
+	case errors.Is(err, service.ErrNotFound):
+		utils.ProblemResponse(c, http.StatusNotFound, service.CodeNotFound, err.Error(), nil)
+	case errors.Is(err, service.ErrInvalidCredentials):
+		utils.ProblemResponse(c, http.StatusUnauthorized, service.CodeInvalidCredentials, err.Error(), nil)
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		utils.ProblemResponse(c, http.StatusUnauthorized, service.CodeInvalidTwoFactorCode, err.Error(), nil)
+	case errors.Is(err, service.ErrLoginChallengeExpired):
+		utils.ProblemResponse(c, http.StatusUnauthorized, service.CodeLoginChallengeExpired, err.Error(), nil)
+	default:
+		utils.InternalErrorResponse(c, err)
+	}
+}
+
+// respondMoved answers a lookup of a redirected slug with the redirect
+// status, a Location header and the redirect in the body.
+func respondMoved(c *gin.Context, moved *service.MovedError, location string) {
+	c.Header("Location", location)
+	utils.ProblemResponse(c, moved.Redirect.StatusCode, service.CodeMoved, moved.Error(),
+		gin.H{"location": location, "redirect": moved.Redirect})
+}
+
+// respondBindError reports a request body or form that failed to decode or
+// validate, listing the offending fields where they are known.
+func respondBindError(c *gin.Context, err error) {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]models.FieldError, 0, len(validationErrs))
+		for _, e := range validationErrs {
+			// Drop the request type name from paths such as "CreatePageRequest.tags[0]"
+			_, field, _ := strings.Cut(e.Namespace(), ".")
+			fields = append(fields, models.FieldError{Field: field, Code: e.Tag(), Message: utils.ValidationMessage(e)})
+		}
+		utils.ProblemResponse(c, http.StatusBadRequest, service.CodeValidationFailed, "request validation failed",
+			gin.H{"errors": fields})
+	case errors.As(err, &typeErr):
+		field := models.FieldError{Field: utils.JSONPath(typeErr.Field), Code: "type", Message: "must be " + utils.JSONTypeName(typeErr.Type)}
+		utils.ProblemResponse(c, http.StatusBadRequest, service.CodeValidationFailed, "request validation failed",
+			gin.H{"errors": []models.FieldError{field}})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		utils.BadRequestResponse(c, "request body must be valid JSON")
+	case errors.Is(err, io.EOF):
+		utils.BadRequestResponse(c, "request body is required")
+	default:
+		utils.BadRequestResponse(c, err.Error())
+	}
+}
+
+// requestFieldName names a request field by its JSON key, or by its form
+// key for multipart requests.
+func requestFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("form"), ","); name != "" && field.Tag.Get("json") == "" {
+		return name
+	}
+	return utils.JSONFieldName(field)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+// etag returns the entity tag of a resource version, e.g. "3" (quoted).
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// setETag sets the ETag header of a versioned resource.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", etag(version))
+}
+
+// notModified answers 304 Not Modified when If-None-Match lists the current
+// version of the resource. It uses weak comparison, as RFC 9110 requires for
+// GET.
+func notModified(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	current := etag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			setETag(c, version)
+			c.AbortWithStatus(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the version If-Match requires for a write, or 0 for
+// an unconditional write ("*" or no header). Entity tags that cannot match
+// any version, such as weak tags, answer 412 directly and return ok false.
+func ifMatchVersion(c *gin.Context) (version int, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	if strings.Contains(header, ",") {
+		utils.BadRequestResponse(c, "If-Match must contain a single entity tag")
+		return 0, false
+	}
+
+	// Strong comparison: weak tags never match
+	unquoted, err := strconv.Unquote(header)
+	if err == nil {
+		version, err = strconv.Atoi(unquoted)
+	}
+	if err != nil || version < 1 {
+		utils.ProblemResponse(c, http.StatusPreconditionFailed, service.CodePreconditionFailed,
+			"If-Match does not match the current version", nil)
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/api/middleware"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type InvitationHandler struct {
+	invitationService *service.InvitationService
+	cookies           *middleware.SessionCookies
+}
+
+func NewInvitationHandler(invitationService *service.InvitationService, cookies *middleware.SessionCookies) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService, cookies: cookies}
+}
+
+// GetInvitations godoc
+// @Summary List invitations
+// @Description List the pending invitations, expired ones included. Requires the admin role.
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string][]models.Invitation "Pending invitations"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Router /invitations [get]
+func (h *InvitationHandler) GetInvitations(c *gin.Context) {
+	invitations, err := h.invitationService.GetInvitations()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// CreateInvitation godoc
+// @Summary Invite user
+// @Description Email an invitation to join with a role. The link carries a single-use token that expires after INVITE_TTL; the invitee chooses a name and password with POST /auth/accept-invite. Requires the admin role.
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.CreateInvitationRequest true "Email and role of the invitee"
+// @Success 201 {object} map[string]models.Invitation "Invitation sent"
+// @Failure 400 {object} models.Problem "Validation error or unknown role"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Admin role required or password login disabled"
+// @Failure 409 {object} models.Problem "User exists or invitation already sent"
+// @Router /invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	invitation, err := h.invitationService.Invite(c.GetInt("user_id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invitation": invitation})
+}
+
+// ResendInvitation godoc
+// @Summary Resend invitation
+// @Description Email a pending invitation again with a new token and expiry; the previous link stops working. Requires the admin role.
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} map[string]models.Invitation "Invitation resent"
+// @Failure 400 {object} models.Problem "Invalid invitation ID"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "Invitation not found"
+// @Router /invitations/{id}/resend [post]
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid invitation ID")
+		return
+	}
+
+	invitation, err := h.invitationService.Resend(c.GetInt("user_id"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitation": invitation})
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke invitation
+// @Description Delete a pending invitation; its link stops working. Requires the admin role.
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} map[string]string "Invitation revoked"
+// @Failure 400 {object} models.Problem "Invalid invitation ID"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "Invitation not found"
+// @Router /invitations/{id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid invitation ID")
+		return
+	}
+
+	if err := h.invitationService.Revoke(c.GetInt("user_id"), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Create an account with the token from an invitation link and the chosen name and password. The account gets the invited email and role and is logged in; useCookie works as for POST /auth/login. The token works once.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.AcceptInvitationRequest true "Invite token, name and password"
+// @Success 201 {object} models.LoginResponse "Account created; new session"
+// @Failure 400 {object} models.Problem "Validation error or invalid token"
+// @Failure 403 {object} models.Problem "Password login disabled"
+// @Failure 409 {object} models.Problem "Email already registered"
+// @Router /auth/accept-invite [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.invitationService.Accept(&req, clientInfo(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if req.UseCookie {
+		setSessionCookies(c, h.cookies, response)
+	}
+	c.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/api/middleware"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type OIDCHandler struct {
+	oidcService  *service.OIDCService
+	cookies      *middleware.SessionCookies
+	postLoginURL string
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService, cookies *middleware.SessionCookies, postLoginURL string) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService, cookies: cookies, postLoginURL: postLoginURL}
+}
+
+// Login godoc
+// @Summary Start single sign-on
+// @Description Redirect the browser to the OpenID Connect identity provider. The login uses the authorization code flow with PKCE and is bound to the browser by a short-lived cookie.
+// @Tags Authentication
+// @Produce json
+// @Success 302 "Redirect to the identity provider"
+// @Failure 500 {object} models.Problem "Identity provider unreachable or misconfigured"
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, state, err := h.oidcService.StartLogin(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.cookies.SetOIDCState(c, state, service.OIDCLoginTTL)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary Finish single sign-on
+// @Description Callback of the identity provider. Validates the state and the ID token, links or provisions the user, maps identity provider groups to roles, starts a cookie session and redirects to the dashboard. Users with two-factor authentication are redirected with a twoFactorChallenge query parameter instead, for POST /auth/login/2fa.
+// @Tags Authentication
+// @Produce json
+// @Param code query string false "Authorization code"
+// @Param state query string true "State of the login"
+// @Param error query string false "Error reported by the identity provider"
+// @Success 302 "Redirect to the dashboard with the session cookies set, or with a two-factor challenge"
+// @Failure 401 {object} models.Problem "Login refused or invalid"
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	state := c.Query("state")
+	if !h.cookies.CheckOIDCState(c, state) {
+		respondError(c, &service.SSOError{Message: "login was not started in this browser; start the login again"})
+		return
+	}
+	if idpError := c.Query("error"); idpError != "" {
+		message := "identity provider refused the login: " + idpError
+		if description := c.Query("error_description"); description != "" {
+			message += " (" + description + ")"
+		}
+		respondError(c, &service.SSOError{Message: message})
+		return
+	}
+
+	response, err := h.oidcService.FinishLogin(c.Request.Context(), state, c.Query("code"), clientInfo(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if response.TwoFactorRequired {
+		h.redirectToChallenge(c, response.ChallengeToken)
+		return
+	}
+
+	h.cookies.Set(c, response.SessionToken)
+	c.Redirect(http.StatusFound, h.postLoginURL)
+}
+
+// redirectToChallenge sends the browser to the dashboard to complete the
+// login with a two-factor code.
+func (h *OIDCHandler) redirectToChallenge(c *gin.Context, challengeToken string) {
+	target, err := url.Parse(h.postLoginURL)
+	if err != nil {
+		utils.InternalErrorResponse(c, err)
+		return
+	}
+	query := target.Query()
+	query.Set("twoFactorChallenge", challengeToken)
+	target.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
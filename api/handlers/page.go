@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xeodocs/xeodocs-dash-api/internal/models"
 	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
 )
 
 type PageHandler struct {
@@ -26,8 +28,8 @@ func NewPageHandler(pageService *service.PageService) *PageHandler {
 // @Security Bearer
 // @Param websiteId query int false "Filter by website ID"
 // @Success 200 {object} map[string][]models.Page "List of pages"
-// @Failure 400 {object} map[string]string "Invalid websiteId parameter"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} models.Problem "Invalid websiteId parameter"
+// @Failure 500 {object} models.Problem "Internal server error"
 // @Router /pages [get]
 func (h *PageHandler) GetPages(c *gin.Context) {
 	// Check if filtering by websiteId
@@ -35,13 +37,13 @@ func (h *PageHandler) GetPages(c *gin.Context) {
 	if websiteIDStr != "" {
 		websiteID, err := strconv.Atoi(websiteIDStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid websiteId parameter"})
+			utils.InvalidParameterResponse(c, "Invalid websiteId parameter")
 			return
 		}
 
 		pages, err := h.pageService.GetPagesByWebsiteID(websiteID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
@@ -51,7 +53,7 @@ func (h *PageHandler) GetPages(c *gin.Context) {
 
 	pages, err := h.pageService.GetAllPages()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -66,47 +68,67 @@ func (h *PageHandler) GetPages(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Param id path int true "Page ID"
+// @Param If-None-Match header string false "ETag of a cached copy; answers 304 Not Modified if it is still current"
 // @Success 200 {object} map[string]models.Page "Page details"
-// @Failure 400 {object} map[string]string "Invalid page ID"
-// @Failure 404 {object} map[string]string "Page not found"
+// @Header 200 {string} ETag "Current version of the page"
+// @Success 304 "Not modified"
+// @Failure 400 {object} models.Problem "Invalid page ID"
+// @Failure 404 {object} models.Problem "Page not found"
 // @Router /pages/{id} [get]
 func (h *PageHandler) GetPage(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page ID"})
+		utils.InvalidParameterResponse(c, "Invalid page ID")
 		return
 	}
 
 	page, err := h.pageService.GetPageByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	if notModified(c, page.Version) {
+		return
+	}
+	setETag(c, page.Version)
 	c.JSON(http.StatusOK, gin.H{"page": page})
 }
 
 // GetPageBySlug godoc
 // @Summary Get page by slug
-// @Description Get a specific page by its slug
+// @Description Get a specific page by its slug. Old slugs of a renamed page answer with a redirect to the new slug.
 // @Tags Pages
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param slug path string true "Page slug"
+// @Param If-None-Match header string false "ETag of a cached copy; answers 304 Not Modified if it is still current"
 // @Success 200 {object} map[string]models.Page "Page details"
-// @Failure 404 {object} map[string]string "Page not found"
+// @Header 200 {string} ETag "Current version of the page"
+// @Success 304 "Not modified"
+// @Failure 301 {object} models.Problem "Page moved; Location header points to the new slug"
+// @Failure 404 {object} models.Problem "Page not found"
 // @Router /pages/slug/{slug} [get]
 func (h *PageHandler) GetPageBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
 	page, err := h.pageService.GetPageBySlug(slug)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		var moved *service.MovedError
+		if errors.As(err, &moved) {
+			respondMoved(c, moved, "/pages/slug/"+moved.Redirect.TargetSlug)
+			return
+		}
+		respondError(c, err)
 		return
 	}
 
+	if notModified(c, page.Version) {
+		return
+	}
+	setETag(c, page.Version)
 	c.JSON(http.StatusOK, gin.H{"page": page})
 }
 
@@ -118,86 +140,319 @@ func (h *PageHandler) GetPageBySlug(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Param page body models.CreatePageRequest true "Page creation data"
-// @Success 201 {object} map[string]models.Page "Page created successfully"
-// @Failure 400 {object} map[string]string "Bad request or validation error"
+// @Success 201 {object} map[string]interface{} "Page created successfully, with lint results"
+// @Header 201 {string} ETag "Version of the new page"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 422 {object} models.Problem "Lint errors block publishing"
+// @Failure 409 {object} models.Problem "Slug already taken"
 // @Router /pages [post]
 func (h *PageHandler) CreatePage(c *gin.Context) {
 	var req models.CreatePageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	page, err := h.pageService.CreatePage(&req)
+	page, result, err := h.pageService.CreatePage(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"page": page})
+	setETag(c, page.Version)
+	c.JSON(http.StatusCreated, gin.H{"page": page, "lint": result.Lint})
 }
 
 // UpdatePage godoc
 // @Summary Update page
-// @Description Update page information
+// @Description Update page information. When the slug changes, the response lists the pages linking to the old slug under slugChange; set rewriteLinks to update those links in the same transaction.
 // @Tags Pages
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path int true "Page ID"
 // @Param page body models.UpdatePageRequest true "Page update data"
-// @Success 200 {object} map[string]models.Page "Page updated successfully"
-// @Failure 400 {object} map[string]string "Bad request or validation error"
-// @Failure 404 {object} map[string]string "Page not found"
+// @Param If-Match header string false "ETag the page must still have; answers 412 Precondition Failed otherwise"
+// @Success 200 {object} map[string]interface{} "Page updated successfully, with lint results and slug change details"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 404 {object} models.Problem "Page not found"
+// @Failure 422 {object} models.Problem "Lint errors block publishing"
+// @Failure 409 {object} models.Problem "Slug already taken"
+// @Failure 412 {object} models.Problem "Page was modified since the ETag was read"
 // @Router /pages/{id} [put]
 func (h *PageHandler) UpdatePage(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page ID"})
+		utils.InvalidParameterResponse(c, "Invalid page ID")
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
 	var req models.UpdatePageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	page, err := h.pageService.UpdatePage(id, &req)
+	page, result, err := h.pageService.UpdatePage(id, &req, expectedVersion)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"page": page})
+	setETag(c, page.Version)
+	response := gin.H{"page": page, "lint": result.Lint}
+	if result.SlugChange != nil {
+		response["slugChange"] = result.SlugChange
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchPage godoc
+// @Summary Patch page
+// @Description Partially update a page with a JSON Merge Patch (RFC 7396) of the fields in CreatePageRequest: members replace existing values and null clears them, e.g. the description, tags or scheduledPublishAt. The patched page is validated like a new page; websiteId cannot be changed.
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Page ID"
+// @Param patch body object true "JSON Merge Patch for the page"
+// @Param rewriteLinks query bool false "Update links in other pages when the slug changes"
+// @Param If-Match header string false "ETag the page must still have; answers 412 Precondition Failed otherwise"
+// @Header 200 {string} ETag "New version of the page"
+// @Success 200 {object} map[string]interface{} "Page updated successfully, with lint results and slug change details"
+// @Failure 400 {object} models.Problem "Invalid patch or validation error"
+// @Failure 404 {object} models.Problem "Page not found"
+// @Failure 409 {object} models.Problem "Slug already taken"
+// @Failure 412 {object} models.Problem "Page was modified since the ETag was read"
+// @Failure 415 {object} models.Problem "Unsupported patch format"
+// @Failure 422 {object} models.Problem "Lint errors block publishing"
+// @Router /pages/{id} [patch]
+func (h *PageHandler) PatchPage(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid page ID")
+		return
+	}
+
+	rewriteLinks := false
+	if rewriteLinksStr := c.Query("rewriteLinks"); rewriteLinksStr != "" {
+		rewriteLinks, err = strconv.ParseBool(rewriteLinksStr)
+		if err != nil {
+			utils.InvalidParameterResponse(c, "Invalid rewriteLinks")
+			return
+		}
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(c)
+	if !ok {
+		return
+	}
+
+	page, result, err := h.pageService.PatchPage(id, patch, rewriteLinks, expectedVersion)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, page.Version)
+	response := gin.H{"page": page, "lint": result.Lint}
+	if result.SlugChange != nil {
+		response["slugChange"] = result.SlugChange
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// LintPage godoc
+// @Summary Lint page content
+// @Description Run the Markdown lint pass against content without saving it. Rule severities come from the website's lint configuration.
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page body models.LintPageRequest true "Content to lint"
+// @Success 200 {object} map[string]models.LintResult "Lint results"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Router /pages/lint [post]
+func (h *PageHandler) LintPage(c *gin.Context) {
+	var req models.LintPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	result, err := h.pageService.LintPage(&req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lint": result})
+}
+
+// BulkPages godoc
+// @Summary Bulk page operation
+// @Description Apply one operation (setStatus, freeze, unfreeze, addTags, removeTags, schedulePublish, delete) to pages selected by ids or by filter; delete moves the pages to the trash. All items are validated first and applied in a single transaction; if any item fails nothing is written. Use dryRun to preview the changes.
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.BulkPageRequest true "Bulk operation"
+// @Success 200 {object} map[string]models.BulkPageResponse "Per-item results"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 422 {object} models.Problem "One or more items failed; nothing was applied"
+// @Router /pages/bulk [post]
+func (h *PageHandler) BulkPages(c *gin.Context) {
+	var req models.BulkPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	result, err := h.pageService.BulkUpdatePages(&req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if result.Failed > 0 {
+		utils.ProblemResponse(c, http.StatusUnprocessableEntity, service.CodeBulkFailed, "bulk operation failed for one or more pages", gin.H{"bulk": result})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bulk": result})
 }
 
 // DeletePage godoc
 // @Summary Delete page
-// @Description Delete a page
+// @Description Move a page to the trash; it can be restored until the trash retention period has passed
 // @Tags Pages
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path int true "Page ID"
+// @Param If-Match header string false "ETag the page must still have; answers 412 Precondition Failed otherwise"
 // @Success 200 {object} map[string]string "Page deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid page ID"
-// @Failure 404 {object} map[string]string "Page not found"
+// @Failure 400 {object} models.Problem "Invalid page ID"
+// @Failure 404 {object} models.Problem "Page not found"
+// @Failure 412 {object} models.Problem "Page was modified since the ETag was read"
 // @Router /pages/{id} [delete]
 func (h *PageHandler) DeletePage(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page ID"})
+		utils.InvalidParameterResponse(c, "Invalid page ID")
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	err = h.pageService.DeletePage(id)
+	err = h.pageService.DeletePage(id, expectedVersion)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Page deleted successfully"})
 }
+
+// GetPageLinks godoc
+// @Summary Get page links
+// @Description Get the internal links from a page (outbound) and to a page (inbound)
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Page ID"
+// @Success 200 {object} map[string]models.PageLinksResponse "Inbound and outbound links"
+// @Failure 400 {object} models.Problem "Invalid page ID"
+// @Failure 404 {object} models.Problem "Page not found"
+// @Router /pages/{id}/links [get]
+func (h *PageHandler) GetPageLinks(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid page ID")
+		return
+	}
+
+	links, err := h.pageService.GetPageLinks(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// GetBrokenLinks godoc
+// @Summary Get broken links report
+// @Description List every internal link in a website that points to a page slug that does not exist
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Success 200 {object} map[string]models.BrokenLinkReport "Broken link report"
+// @Failure 400 {object} models.Problem "Invalid website ID"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Router /websites/{id}/broken-links [get]
+func (h *PageHandler) GetBrokenLinks(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	report, err := h.pageService.GetBrokenLinks(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// RebuildLinks godoc
+// @Summary Rebuild link graph
+// @Description Re-extract internal links from every page of a website
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Success 200 {object} map[string]int "Number of pages indexed"
+// @Failure 400 {object} models.Problem "Invalid website ID"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Router /websites/{id}/links/rebuild [post]
+func (h *PageHandler) RebuildLinks(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	pages, err := h.pageService.RebuildLinks(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pagesIndexed": pages})
+}
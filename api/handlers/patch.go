@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch (RFC 7396)
+// bodies. Plain application/json is accepted as well.
+const MergePatchContentType = "application/merge-patch+json"
+
+// readMergePatch reads a JSON Merge Patch request body. Other patch formats,
+// such as JSON Patch (RFC 6902), answer 415 Unsupported Media Type.
+func readMergePatch(c *gin.Context) ([]byte, bool) {
+	if header := c.GetHeader("Content-Type"); header != "" {
+		mediaType, _, err := mime.ParseMediaType(header)
+		if err != nil || (mediaType != MergePatchContentType && mediaType != "application/json") {
+			c.Header("Accept-Patch", MergePatchContentType)
+			utils.ProblemResponse(c, http.StatusUnsupportedMediaType, utils.CodeUnsupportedMedia,
+				"patch must be sent as "+MergePatchContentType, nil)
+			return nil, false
+		}
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return nil, false
+	}
+	return patch, true
+}
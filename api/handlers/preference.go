@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type PreferenceHandler struct {
+	preferenceService *service.PreferenceService
+}
+
+func NewPreferenceHandler(preferenceService *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{preferenceService: preferenceService}
+}
+
+// GetPreferences godoc
+// @Summary Get preferences
+// @Description Get the dashboard preferences of the current user, such as UI language, default website, notification settings and editor options. Unset keys are left out.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]models.UserPreferences "Preferences"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Router /users/me/preferences [get]
+func (h *PreferenceHandler) GetPreferences(c *gin.Context) {
+	prefs, err := h.preferenceService.GetPreferences(c.GetInt("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// ReplacePreferences godoc
+// @Summary Replace preferences
+// @Description Set all preferences of the current user; keys left out are cleared. Unknown keys and invalid values are refused, and the preferences may take at most 16 KiB.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param preferences body models.UserPreferences true "Preferences"
+// @Success 200 {object} map[string]models.UserPreferences "Preferences updated"
+// @Failure 400 {object} models.Problem "Validation error"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Router /users/me/preferences [put]
+func (h *PreferenceHandler) ReplacePreferences(c *gin.Context) {
+	raw, err := c.GetRawData()
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	prefs, err := h.preferenceService.ReplacePreferences(c.GetInt("user_id"), raw)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// PatchPreferences godoc
+// @Summary Patch preferences
+// @Description Partially update the preferences of the current user with a JSON Merge Patch (RFC 7396): members replace existing values and null clears them. The result is validated like a full replacement.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param patch body object true "JSON Merge Patch for the preferences"
+// @Success 200 {object} map[string]models.UserPreferences "Preferences updated"
+// @Failure 400 {object} models.Problem "Invalid patch or validation error"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 415 {object} models.Problem "Unsupported patch format"
+// @Router /users/me/preferences [patch]
+func (h *PreferenceHandler) PatchPreferences(c *gin.Context) {
+	patch, ok := readMergePatch(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.preferenceService.PatchPreferences(c.GetInt("user_id"), patch)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type RedirectHandler struct {
+	redirectService *service.RedirectService
+}
+
+func NewRedirectHandler(redirectService *service.RedirectService) *RedirectHandler {
+	return &RedirectHandler{redirectService: redirectService}
+}
+
+// GetRedirects godoc
+// @Summary Get all redirects
+// @Description Get list of slug redirects, optionally filtered by website and resource type
+// @Tags Redirects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param websiteId query int false "Filter by website ID"
+// @Param resourceType query string false "Filter by resource type (page or website)"
+// @Success 200 {object} map[string][]models.Redirect "List of redirects"
+// @Failure 400 {object} models.Problem "Invalid query parameter"
+// @Failure 500 {object} models.Problem "Internal server error"
+// @Router /redirects [get]
+func (h *RedirectHandler) GetRedirects(c *gin.Context) {
+	websiteID := 0
+	if websiteIDStr := c.Query("websiteId"); websiteIDStr != "" {
+		var err error
+		websiteID, err = strconv.Atoi(websiteIDStr)
+		if err != nil {
+			utils.InvalidParameterResponse(c, "Invalid websiteId parameter")
+			return
+		}
+	}
+
+	resourceType := c.Query("resourceType")
+	if resourceType != "" && resourceType != models.RedirectTypePage && resourceType != models.RedirectTypeWebsite {
+		utils.InvalidParameterResponse(c, "Invalid resourceType parameter")
+		return
+	}
+
+	redirects, err := h.redirectService.GetRedirects(websiteID, resourceType)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"redirects": redirects})
+}
+
+// GetRedirect godoc
+// @Summary Get redirect by ID
+// @Description Get a specific redirect by its ID
+// @Tags Redirects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Redirect ID"
+// @Success 200 {object} map[string]models.Redirect "Redirect details"
+// @Failure 400 {object} models.Problem "Invalid redirect ID"
+// @Failure 404 {object} models.Problem "Redirect not found"
+// @Router /redirects/{id} [get]
+func (h *RedirectHandler) GetRedirect(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid redirect ID")
+		return
+	}
+
+	redirect, err := h.redirectService.GetRedirectByID(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"redirect": redirect})
+}
+
+// CreateRedirect godoc
+// @Summary Create new redirect
+// @Description Create a manual redirect from an unused slug to a page or website
+// @Tags Redirects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param redirect body models.CreateRedirectRequest true "Redirect creation data"
+// @Success 201 {object} map[string]models.Redirect "Redirect created successfully"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 409 {object} models.Problem "Slug in use or redirect already exists"
+// @Router /redirects [post]
+func (h *RedirectHandler) CreateRedirect(c *gin.Context) {
+	var req models.CreateRedirectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	redirect, err := h.redirectService.CreateRedirect(&req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"redirect": redirect})
+}
+
+// UpdateRedirect godoc
+// @Summary Update redirect
+// @Description Update a redirect's old slug, target or status code
+// @Tags Redirects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Redirect ID"
+// @Param redirect body models.UpdateRedirectRequest true "Redirect update data"
+// @Success 200 {object} map[string]models.Redirect "Redirect updated successfully"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 404 {object} models.Problem "Redirect not found"
+// @Failure 409 {object} models.Problem "Slug in use or redirect already exists"
+// @Router /redirects/{id} [put]
+func (h *RedirectHandler) UpdateRedirect(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid redirect ID")
+		return
+	}
+
+	var req models.UpdateRedirectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	redirect, err := h.redirectService.UpdateRedirect(id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"redirect": redirect})
+}
+
+// DeleteRedirect godoc
+// @Summary Delete redirect
+// @Description Delete a redirect
+// @Tags Redirects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Redirect ID"
+// @Success 200 {object} map[string]string "Redirect deleted successfully"
+// @Failure 400 {object} models.Problem "Invalid redirect ID"
+// @Failure 404 {object} models.Problem "Redirect not found"
+// @Router /redirects/{id} [delete]
+func (h *RedirectHandler) DeleteRedirect(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid redirect ID")
+		return
+	}
+
+	err = h.redirectService.DeleteRedirect(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Redirect deleted successfully"})
+}
+
+// ExportRedirects godoc
+// @Summary Export website redirects
+// @Description Export the page redirects of a website for the static site, as JSON or as a Netlify-style _redirects file
+// @Tags Redirects
+// @Produce json
+// @Produce plain
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Param format query string false "Export format: json (default) or netlify"
+// @Success 200 {string} string "Redirects export"
+// @Failure 400 {object} models.Problem "Invalid website ID or format"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Router /websites/{id}/redirects/export [get]
+func (h *RedirectHandler) ExportRedirects(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "netlify" {
+		utils.InvalidParameterResponse(c, "Invalid format parameter")
+		return
+	}
+
+	data, contentType, err := h.redirectService.ExportRedirects(id, format)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
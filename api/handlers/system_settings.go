@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+)
+
+type SystemSettingsHandler struct {
+	settingsService *service.SettingsService
+}
+
+func NewSystemSettingsHandler(settingsService *service.SettingsService) *SystemSettingsHandler {
+	return &SystemSettingsHandler{settingsService: settingsService}
+}
+
+// GetSettings godoc
+// @Summary List system settings
+// @Description List the runtime system settings with their current value, default, bounds and source: "env" when an environment variable sets the value, "database" when it was set through this API, else "default" (admin only)
+// @Tags System
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string][]models.SystemSetting "System settings"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Router /system/settings [get]
+func (h *SystemSettingsHandler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"settings": h.settingsService.GetSettings()})
+}
+
+// PatchSettings godoc
+// @Summary Update system settings
+// @Description Change runtime system settings with a JSON Merge Patch (RFC 7396) keyed by setting, e.g. {"password.minLength": 10}; null restores the default. Settings set by an environment variable cannot be changed. Changes apply to every replica within 30 seconds and are recorded in the admin's activity log (admin only).
+// @Tags System
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param patch body object true "JSON Merge Patch keyed by setting"
+// @Success 200 {object} map[string][]models.SystemSetting "Settings updated"
+// @Failure 400 {object} models.Problem "Invalid patch or validation error"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 415 {object} models.Problem "Unsupported patch format"
+// @Router /system/settings [patch]
+func (h *SystemSettingsHandler) PatchSettings(c *gin.Context) {
+	patch, ok := readMergePatch(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.settingsService.UpdateSettings(c.GetInt("user_id"), patch)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": list})
+}
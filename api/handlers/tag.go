@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// GetTags godoc
+// @Summary Get website tags
+// @Description Get the tags used by the pages of a website, with the number of pages using each tag
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Success 200 {object} map[string][]models.Tag "List of tags"
+// @Failure 400 {object} models.Problem "Invalid website ID"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Router /websites/{id}/tags [get]
+func (h *TagHandler) GetTags(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	tags, err := h.tagService.GetTags(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// RenameTag godoc
+// @Summary Rename tag
+// @Description Rename a tag on every page of a website. Renaming onto an existing tag is refused; merge the tags instead.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Param request body models.RenameTagRequest true "Current and new tag name"
+// @Success 200 {object} map[string]models.Tag "Renamed tag"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 404 {object} models.Problem "Tag not found"
+// @Failure 409 {object} models.Problem "Target tag already exists"
+// @Router /websites/{id}/tags/rename [post]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	var req models.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	tag, err := h.tagService.RenameTag(id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
+
+// MergeTags godoc
+// @Summary Merge tags
+// @Description Replace the source tags with the target tag on every page of a website. The target tag is created if needed and the source tags are deleted.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Param request body models.MergeTagsRequest true "Source tags and target tag"
+// @Success 200 {object} map[string]models.Tag "Merged tag"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 404 {object} models.Problem "Tag not found"
+// @Router /websites/{id}/tags/merge [post]
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	var req models.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	tag, err := h.tagService.MergeTags(id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// GetTrash godoc
+// @Summary List trash
+// @Description List deleted websites, pages and users, newest first, with the time each will be purged for good. Pages deleted with their website are listed under the website (admin only).
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param type query string false "Only list items of this type" Enums(website, page, user)
+// @Success 200 {object} map[string][]models.TrashItem "Items in the trash"
+// @Failure 400 {object} models.Problem "Unknown type"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Router /trash [get]
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	items, err := h.trashService.GetTrash(c.Query("type"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// RestoreItem godoc
+// @Summary Restore from trash
+// @Description Restore a deleted website (with the pages deleted along with it), page or user. The slugs, name or email must still be free; a page cannot be restored while its website is in the trash (admin only).
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param type path string true "Item type" Enums(website, page, user)
+// @Param id path int true "Item ID"
+// @Success 200 {object} map[string]string "Item restored successfully"
+// @Failure 400 {object} models.Problem "Invalid type or ID"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "Item not in the trash"
+// @Failure 409 {object} models.Problem "Slug, name or email taken, or website still in the trash"
+// @Router /trash/{type}/{id}/restore [post]
+func (h *TrashHandler) RestoreItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid item ID")
+		return
+	}
+
+	if err := h.trashService.Restore(c.Param("type"), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item restored successfully"})
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// GetStatus godoc
+// @Summary Get two-factor status
+// @Description Show whether the current user has two-factor authentication enabled, how many recovery codes are left and whether a role requires it. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.TwoFactorStatus "Two-factor status"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Called with an API token"
+// @Router /auth/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	status, err := h.twoFactorService.GetStatus(c.GetInt("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Setup godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret for an authenticator app, as text and as an otpauth:// URI to show as a QR code. Enrollment completes with POST /auth/2fa/enable; calling this again replaces the pending secret. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.TwoFactorSetupResponse "Pending TOTP secret"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Called with an API token"
+// @Failure 409 {object} models.Problem "Two-factor authentication already enabled"
+// @Router /auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	response, err := h.twoFactorService.Setup(c.GetInt("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Enable godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm the pending enrollment with a code from the authenticator app. The response carries the recovery codes; they are shown only once. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.TwoFactorCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} models.RecoveryCodesResponse "Two-factor authentication enabled"
+// @Failure 400 {object} models.Problem "Incorrect code"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Called with an API token"
+// @Failure 409 {object} models.Problem "No enrollment in progress or already enabled"
+// @Router /auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(c.GetInt("user_id"), req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off with a current code or a recovery code. Not allowed while a role of the user requires it. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.TwoFactorCodeRequest true "Code from the authenticator app or a recovery code"
+// @Success 200 {object} map[string]string "Two-factor authentication disabled"
+// @Failure 400 {object} models.Problem "Incorrect code"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Required by a role or called with an API token"
+// @Failure 409 {object} models.Problem "Two-factor authentication not enabled"
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.GetInt("user_id"), req.Code); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes of the current user after checking a current code or a recovery code. The previous codes stop working. Requires a login session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.TwoFactorCodeRequest true "Code from the authenticator app or a recovery code"
+// @Success 200 {object} models.RecoveryCodesResponse "New recovery codes"
+// @Failure 400 {object} models.Problem "Incorrect code"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 403 {object} models.Problem "Called with an API token"
+// @Failure 409 {object} models.Problem "Two-factor authentication not enabled"
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.GetInt("user_id"), req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ResetUserTwoFactor godoc
+// @Summary Reset two-factor authentication
+// @Description Turn off the two-factor authentication of a user who lost the device and the recovery codes. If a role requires it, the user must enroll again on next login. Requires the admin role.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]models.User "Two-factor authentication reset"
+// @Failure 400 {object} models.Problem "Invalid user ID"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "User not found"
+// @Router /users/{id}/2fa [delete]
+func (h *TwoFactorHandler) ResetUserTwoFactor(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid user ID")
+		return
+	}
+
+	user, err := h.twoFactorService.Reset(c.GetInt("user_id"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// SetRoleRequirement godoc
+// @Summary Require two-factor authentication for a role
+// @Description Turn the two-factor requirement of a role on or off. Users with a role that requires it must enroll before they can use any other endpoint. Requires the admin role.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Role ID"
+// @Param request body models.SetRoleTwoFactorRequest true "Whether the role requires two-factor authentication"
+// @Success 200 {object} map[string]models.Role "Role updated"
+// @Failure 400 {object} models.Problem "Invalid role ID or validation error"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "Role not found"
+// @Router /roles/{id}/2fa [put]
+func (h *TwoFactorHandler) SetRoleRequirement(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid role ID")
+		return
+	}
+
+	var req models.SetRoleTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	role, err := h.twoFactorService.SetRoleRequirement(c.GetInt("user_id"), id, *req.Required)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role": role})
+}
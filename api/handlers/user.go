@@ -3,71 +3,130 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/api/middleware"
 	"github.com/xeodocs/xeodocs-dash-api/internal/models"
 	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService       *service.UserService
+	preferenceService *service.PreferenceService
+	cookies           *middleware.SessionCookies
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, preferenceService *service.PreferenceService,
+	cookies *middleware.SessionCookies) *UserHandler {
+	return &UserHandler{userService: userService, preferenceService: preferenceService, cookies: cookies}
 }
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user with email and password
+// @Description Authenticate user with email and password. With useCookie the session token is set in an HttpOnly cookie instead of being returned, and the response carries the CSRF token that cookie-authenticated mutating requests must send in X-CSRF-Token. Users with two-factor authentication get twoFactorRequired and a challengeToken instead of a session; complete the login with POST /auth/login/2fa.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param credentials body models.LoginRequest true "Login credentials"
 // @Success 200 {object} models.LoginResponse "Login successful"
-// @Failure 400 {object} map[string]string "Bad request"
-// @Failure 401 {object} map[string]string "Invalid credentials"
+// @Failure 400 {object} models.Problem "Bad request"
+// @Failure 401 {object} models.Problem "Invalid credentials"
+// @Failure 429 {object} models.Problem "Too many failed logins; see Retry-After"
 // @Router /auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	response, err := h.userService.Login(&req)
+	response, err := h.userService.Login(&req, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	if req.UseCookie && !response.TwoFactorRequired {
+		setSessionCookies(c, h.cookies, response)
+	}
 	c.JSON(http.StatusOK, response)
 }
 
+// CompleteTwoFactorLogin godoc
+// @Summary Complete two-factor login
+// @Description Finish a login that returned a challenge with a code from the authenticator app or a recovery code. A challenge expires after 5 minutes or 5 wrong codes; wrong codes also count as failed logins of the account. useCookie works as for POST /auth/login.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} models.LoginResponse "Login successful"
+// @Failure 400 {object} models.Problem "Bad request"
+// @Failure 401 {object} models.Problem "Wrong code, or the challenge expired"
+// @Failure 429 {object} models.Problem "Too many failed logins; see Retry-After"
+// @Router /auth/login/2fa [post]
+func (h *UserHandler) CompleteTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.userService.CompleteTwoFactorLogin(&req, clientInfo(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if req.UseCookie {
+		setSessionCookies(c, h.cookies, response)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// setSessionCookies moves a new session into the session cookies; the
+// response then carries the CSRF token instead of the session token.
+func setSessionCookies(c *gin.Context, cookies *middleware.SessionCookies, response *models.LoginResponse) {
+	cookies.Set(c, response.SessionToken)
+	response.CSRFToken = service.CSRFToken(response.SessionToken)
+	response.SessionToken = ""
+}
+
 // Logout godoc
 // @Summary User logout
-// @Description Logout user and invalidate session
+// @Description Logout user and invalidate session. Cookie sessions also get their cookies cleared and must send X-CSRF-Token.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Param X-CSRF-Token header string false "CSRF token of a cookie session"
 // @Success 200 {object} map[string]string "Logout successful"
-// @Failure 400 {object} map[string]string "Bad request"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} models.Problem "Bad request"
+// @Failure 403 {object} models.Problem "Missing or invalid CSRF token"
+// @Failure 500 {object} models.Problem "Internal server error"
 // @Router /auth/logout [post]
 func (h *UserHandler) Logout(c *gin.Context) {
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization header required"})
+	var token string
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		// Extract token from "Bearer <token>" format
+		token = strings.TrimPrefix(authHeader, "Bearer ")
+	} else if cookieToken, ok := h.cookies.Token(c); ok {
+		if !h.cookies.CheckCSRF(c, cookieToken) {
+			utils.ProblemResponse(c, http.StatusForbidden, utils.CodeCSRFFailed,
+				"missing or invalid "+middleware.CSRFHeader+" header", nil)
+			return
+		}
+		token = cookieToken
+		h.cookies.Clear(c)
+	} else {
+		utils.BadRequestResponse(c, "Authorization header or session cookie required")
 		return
 	}
 
-	// Extract token from "Bearer <token>" format
-	token := authHeader[7:] // Remove "Bearer " prefix
-
 	err := h.userService.Logout(token)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		respondError(c, err)
 		return
 	}
 
@@ -82,12 +141,12 @@ func (h *UserHandler) Logout(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Success 200 {object} map[string][]models.User "List of users"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 500 {object} models.Problem "Internal server error"
 // @Router /users [get]
 func (h *UserHandler) GetUsers(c *gin.Context) {
 	users, err := h.userService.GetAllUsers()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -102,113 +161,189 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Param id path int true "User ID"
+// @Param If-None-Match header string false "ETag of a cached copy; answers 304 Not Modified if it is still current"
 // @Success 200 {object} map[string]models.User "User details"
-// @Failure 400 {object} map[string]string "Invalid user ID"
-// @Failure 404 {object} map[string]string "User not found"
+// @Header 200 {string} ETag "Current version of the user"
+// @Success 304 "Not modified"
+// @Failure 400 {object} models.Problem "Invalid user ID"
+// @Failure 404 {object} models.Problem "User not found"
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		utils.InvalidParameterResponse(c, "Invalid user ID")
 		return
 	}
 
 	user, err := h.userService.GetUserByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	if notModified(c, user.Version) {
+		return
+	}
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
 
 // CreateUser godoc
 // @Summary Create new user
-// @Description Create a new user account
+// @Description Create a new user account (admin only)
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param user body models.CreateUserRequest true "User creation data"
 // @Success 201 {object} map[string]models.User "User created successfully"
-// @Failure 400 {object} map[string]string "Bad request or validation error"
+// @Header 201 {string} ETag "Version of the new user"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 409 {object} models.Problem "Email already taken"
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req models.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	user, err := h.userService.CreateUser(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, user.Version)
 	c.JSON(http.StatusCreated, gin.H{"user": user})
 }
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update user information
+// @Description Update user information. Users can update themselves; only admins can update other users.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path int true "User ID"
 // @Param user body models.UpdateUserRequest true "User update data"
+// @Param If-Match header string false "ETag the user must still have; answers 412 Precondition Failed otherwise"
 // @Success 200 {object} map[string]models.User "User updated successfully"
-// @Failure 400 {object} map[string]string "Bad request or validation error"
-// @Failure 404 {object} map[string]string "User not found"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 403 {object} models.Problem "Only admins can update other users"
+// @Failure 404 {object} models.Problem "User not found"
+// @Failure 409 {object} models.Problem "Email already taken"
+// @Failure 412 {object} models.Problem "User was modified since the ETag was read"
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		utils.InvalidParameterResponse(c, "Invalid user ID")
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
+		return
+	}
+
+	user, err := h.userService.UpdateUser(c.GetInt("user_id"), id, &req, expectedVersion)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// PatchUser godoc
+// @Summary Patch user
+// @Description Partially update a user with a JSON Merge Patch (RFC 7396) of the fields in UserProfile. The patched user is validated like a new user. Users can patch themselves; only admins can patch other users.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "User ID"
+// @Param patch body models.UserProfile true "JSON Merge Patch for the user"
+// @Param If-Match header string false "ETag the user must still have; answers 412 Precondition Failed otherwise"
+// @Header 200 {string} ETag "New version of the user"
+// @Success 200 {object} map[string]models.User "User updated successfully"
+// @Failure 400 {object} models.Problem "Invalid patch or validation error"
+// @Failure 403 {object} models.Problem "Only admins can update other users"
+// @Failure 404 {object} models.Problem "User not found"
+// @Failure 409 {object} models.Problem "Email already taken"
+// @Failure 412 {object} models.Problem "User was modified since the ETag was read"
+// @Failure 415 {object} models.Problem "Unsupported patch format"
+// @Router /users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid user ID")
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(c)
+	if !ok {
 		return
 	}
 
-	user, err := h.userService.UpdateUser(id, &req)
+	user, err := h.userService.PatchUser(c.GetInt("user_id"), id, patch, expectedVersion)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
 
 // DeleteUser godoc
 // @Summary Delete user
-// @Description Delete a user account
+// @Description Move a user account to the trash and end its sessions; it can be restored until the trash retention period has passed. Users can delete themselves; only admins can delete other users.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path int true "User ID"
+// @Param If-Match header string false "ETag the user must still have; answers 412 Precondition Failed otherwise"
 // @Success 200 {object} map[string]string "User deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid user ID"
-// @Failure 404 {object} map[string]string "User not found"
+// @Failure 400 {object} models.Problem "Invalid user ID"
+// @Failure 403 {object} models.Problem "Only admins can delete other users"
+// @Failure 404 {object} models.Problem "User not found"
+// @Failure 412 {object} models.Problem "User was modified since the ETag was read"
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		utils.InvalidParameterResponse(c, "Invalid user ID")
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	err = h.userService.DeleteUser(id)
+	err = h.userService.DeleteUser(c.GetInt("user_id"), id, expectedVersion)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -217,20 +352,295 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 // GetCurrentUser godoc
 // @Summary Get current user
-// @Description Get information about the currently authenticated user
+// @Description Get information about the currently authenticated user together with the user's preferences
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Security Bearer
-// @Success 200 {object} map[string]models.User "Current user information"
-// @Failure 401 {object} map[string]string "User not authenticated"
+// @Success 200 {object} models.CurrentUserResponse "Current user information"
+// @Failure 401 {object} models.Problem "User not authenticated"
 // @Router /auth/me [get]
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	prefs, err := h.preferenceService.GetPreferences(c.GetInt("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CurrentUserResponse{User: user.(*models.User), Preferences: prefs})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password of the current user after verifying the current one. All sessions and API tokens of the user are ended and a new session token is returned.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} models.LoginResponse "Password changed; new session"
+// @Failure 400 {object} models.Problem "Validation error or incorrect current password"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Router /auth/change-password [post]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.userService.ChangePassword(c.GetInt("user_id"), &req, clientInfo(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// A cookie session is replaced by a new cookie session
+	if c.GetBool("cookie_auth") {
+		setSessionCookies(c, h.cookies, response)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RequestPasswordReset godoc
+// @Summary Request password reset
+// @Description Email a single-use, time-limited password reset link. The response is the same whether or not the email belongs to an account. Requests are throttled per email and per client IP like failed logins.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.PasswordResetRequest true "Account email"
+// @Success 202 {object} map[string]string "Reset link sent if the account exists"
+// @Failure 400 {object} models.Problem "Validation error"
+// @Failure 429 {object} models.Problem "Too many reset requests for the email or from the client; see Retry-After"
+// @Router /auth/password-reset [post]
+func (h *UserHandler) RequestPasswordReset(c *gin.Context) {
+	var req models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.userService.RequestPasswordReset(req.Email, clientInfo(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a password reset link has been sent"})
+}
+
+// ConfirmPasswordReset godoc
+// @Summary Reset password
+// @Description Set a new password with a token from a reset link. The token works once; all sessions and API tokens of the user are ended.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ConfirmPasswordResetRequest true "Reset token and new password"
+// @Success 200 {object} map[string]string "Password reset"
+// @Failure 400 {object} models.Problem "Validation error or invalid token"
+// @Router /auth/password-reset/confirm [post]
+func (h *UserHandler) ConfirmPasswordReset(c *gin.Context) {
+	var req models.ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.userService.ResetPassword(&req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// ForcePasswordReset godoc
+// @Summary Force password reset
+// @Description Make a user change the password on next login and end all of the user's sessions and API tokens. Requires the admin role.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]models.User "Password reset forced"
+// @Failure 400 {object} models.Problem "Invalid user ID"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "User not found"
+// @Router /users/{id}/force-password-reset [post]
+func (h *UserHandler) ForcePasswordReset(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid user ID")
 		return
 	}
 
+	user, err := h.userService.ForcePasswordReset(c.GetInt("user_id"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
+
+// UnlockUser godoc
+// @Summary Unlock user
+// @Description Clear the failed logins and any lockout of a user's account. Requires the admin role.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]models.User "User unlocked"
+// @Failure 400 {object} models.Problem "Invalid user ID"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "User not found"
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid user ID")
+		return
+	}
+
+	user, err := h.userService.UnlockUser(c.GetInt("user_id"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// GetSessions godoc
+// @Summary List sessions
+// @Description List the active sessions of the current user with their user agent, IP address and last activity. The session making the request has current set.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string][]models.UserSession "Active sessions"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Router /auth/sessions [get]
+func (h *UserHandler) GetSessions(c *gin.Context) {
+	sessions, err := h.userService.GetSessions(c.GetInt("user_id"), c.GetInt("session_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description End one of the current user's sessions, e.g. on a lost device
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Session ID"
+// @Success 200 {object} map[string]string "Session revoked"
+// @Failure 400 {object} models.Problem "Invalid session ID"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Failure 404 {object} models.Problem "Session not found"
+// @Router /auth/sessions/{id} [delete]
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid session ID")
+		return
+	}
+
+	err = h.userService.RevokeSession(c.GetInt("user_id"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeOtherSessions godoc
+// @Summary Log out everywhere else
+// @Description End every session of the current user except the one making the request
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]int "Number of revoked sessions"
+// @Failure 401 {object} models.Problem "User not authenticated"
+// @Router /auth/sessions/revoke-others [post]
+func (h *UserHandler) RevokeOtherSessions(c *gin.Context) {
+	revoked, err := h.userService.RevokeOtherSessions(c.GetInt("user_id"), c.GetInt("session_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description End every session of a user, e.g. after a suspected compromise. Requires the admin role.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]int "Number of revoked sessions"
+// @Failure 400 {object} models.Problem "Invalid user ID"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Failure 404 {object} models.Problem "User not found"
+// @Router /users/{id}/sessions [delete]
+func (h *UserHandler) RevokeUserSessions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid user ID")
+		return
+	}
+
+	revoked, err := h.userService.RevokeAllSessions(c.GetInt("user_id"), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// GetRoles godoc
+// @Summary Get all roles
+// @Description List the roles with their permissions and whether they require two-factor authentication. Requires the admin role.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string][]models.Role "List of roles"
+// @Failure 403 {object} models.Problem "Admin role required"
+// @Router /roles [get]
+func (h *UserHandler) GetRoles(c *gin.Context) {
+	roles, err := h.userService.GetRoles()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// clientInfo describes the client of a request for the user service.
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
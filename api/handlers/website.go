@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xeodocs/xeodocs-dash-api/internal/models"
 	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
 )
 
 type WebsiteHandler struct {
@@ -25,12 +27,12 @@ func NewWebsiteHandler(websiteService *service.WebsiteService) *WebsiteHandler {
 // @Produce json
 // @Security Bearer
 // @Success 200 {object} map[string][]models.Website "List of websites"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 500 {object} models.Problem "Internal server error"
 // @Router /websites [get]
 func (h *WebsiteHandler) GetWebsites(c *gin.Context) {
 	websites, err := h.websiteService.GetAllWebsites()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -45,74 +47,97 @@ func (h *WebsiteHandler) GetWebsites(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Param id path int true "Website ID"
+// @Param If-None-Match header string false "ETag of a cached copy; answers 304 Not Modified if it is still current"
 // @Success 200 {object} map[string]models.Website "Website details"
-// @Failure 400 {object} map[string]string "Invalid website ID"
-// @Failure 404 {object} map[string]string "Website not found"
+// @Header 200 {string} ETag "Current version of the website"
+// @Success 304 "Not modified"
+// @Failure 400 {object} models.Problem "Invalid website ID"
+// @Failure 404 {object} models.Problem "Website not found"
 // @Router /websites/{id} [get]
 func (h *WebsiteHandler) GetWebsite(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid website ID"})
+		utils.InvalidParameterResponse(c, "Invalid website ID")
 		return
 	}
 
 	website, err := h.websiteService.GetWebsiteByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	if notModified(c, website.Version) {
+		return
+	}
+	setETag(c, website.Version)
 	c.JSON(http.StatusOK, gin.H{"website": website})
 }
 
 // GetWebsiteBySlug godoc
 // @Summary Get website by slug
-// @Description Get a specific website by its slug
+// @Description Get a specific website by its slug. Old slugs of a renamed website answer with a redirect to the new slug.
 // @Tags Websites
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param slug path string true "Website slug"
+// @Param If-None-Match header string false "ETag of a cached copy; answers 304 Not Modified if it is still current"
 // @Success 200 {object} map[string]models.Website "Website details"
-// @Failure 404 {object} map[string]string "Website not found"
+// @Header 200 {string} ETag "Current version of the website"
+// @Success 304 "Not modified"
+// @Failure 301 {object} models.Problem "Website moved; Location header points to the new slug"
+// @Failure 404 {object} models.Problem "Website not found"
 // @Router /websites/slug/{slug} [get]
 func (h *WebsiteHandler) GetWebsiteBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
 	website, err := h.websiteService.GetWebsiteBySlug(slug)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		var moved *service.MovedError
+		if errors.As(err, &moved) {
+			respondMoved(c, moved, "/websites/slug/"+moved.Redirect.TargetSlug)
+			return
+		}
+		respondError(c, err)
 		return
 	}
 
+	if notModified(c, website.Version) {
+		return
+	}
+	setETag(c, website.Version)
 	c.JSON(http.StatusOK, gin.H{"website": website})
 }
 
 // CreateWebsite godoc
 // @Summary Create new website
-// @Description Create a new website
+// @Description Create a new website. The config is validated against the schema from GET /websites/config-schema; invalid fields are listed in "fields".
 // @Tags Websites
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param website body models.CreateWebsiteRequest true "Website creation data"
 // @Success 201 {object} map[string]models.Website "Website created successfully"
-// @Failure 400 {object} map[string]string "Bad request or validation error"
+// @Header 201 {string} ETag "Version of the new website"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 409 {object} models.Problem "Slug already taken"
 // @Router /websites [post]
 func (h *WebsiteHandler) CreateWebsite(c *gin.Context) {
 	var req models.CreateWebsiteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	website, err := h.websiteService.CreateWebsite(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, website.Version)
 	c.JSON(http.StatusCreated, gin.H{"website": website})
 }
 
@@ -125,58 +150,190 @@ func (h *WebsiteHandler) CreateWebsite(c *gin.Context) {
 // @Security Bearer
 // @Param id path int true "Website ID"
 // @Param website body models.UpdateWebsiteRequest true "Website update data"
+// @Param If-Match header string false "ETag the website must still have; answers 412 Precondition Failed otherwise"
 // @Success 200 {object} map[string]models.Website "Website updated successfully"
-// @Failure 400 {object} map[string]string "Bad request or validation error"
-// @Failure 404 {object} map[string]string "Website not found"
+// @Failure 400 {object} models.Problem "Bad request or validation error"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Failure 409 {object} models.Problem "Slug already taken"
+// @Failure 412 {object} models.Problem "Website was modified since the ETag was read"
 // @Router /websites/{id} [put]
 func (h *WebsiteHandler) UpdateWebsite(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid website ID"})
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
 	var req models.UpdateWebsiteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
+		return
+	}
+
+	website, err := h.websiteService.UpdateWebsite(id, &req, expectedVersion)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, website.Version)
+	c.JSON(http.StatusOK, gin.H{"website": website})
+}
+
+// PatchWebsite godoc
+// @Summary Patch website
+// @Description Partially update a website with a JSON Merge Patch (RFC 7396) of the fields in CreateWebsiteRequest: members replace existing values, null clears them and the config is merged member by member. The patched website is validated like a new website.
+// @Tags Websites
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Param patch body object true "JSON Merge Patch for the website"
+// @Param If-Match header string false "ETag the website must still have; answers 412 Precondition Failed otherwise"
+// @Header 200 {string} ETag "New version of the website"
+// @Success 200 {object} map[string]models.Website "Website updated successfully"
+// @Failure 400 {object} models.Problem "Invalid patch or validation error"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Failure 409 {object} models.Problem "Slug already taken"
+// @Failure 412 {object} models.Problem "Website was modified since the ETag was read"
+// @Failure 415 {object} models.Problem "Unsupported patch format"
+// @Router /websites/{id} [patch]
+func (h *WebsiteHandler) PatchWebsite(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.InvalidParameterResponse(c, "Invalid website ID")
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(c)
+	if !ok {
+		return
+	}
+
+	website, err := h.websiteService.PatchWebsite(id, patch, expectedVersion)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	website, err := h.websiteService.UpdateWebsite(id, &req)
+	setETag(c, website.Version)
+	c.JSON(http.StatusOK, gin.H{"website": website})
+}
+
+// GetConfigSchema godoc
+// @Summary Get website config schema
+// @Description Get the JSON Schema of the website configuration, for editors and client-side validation
+// @Tags Websites
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{} "JSON Schema"
+// @Router /websites/config-schema [get]
+func (h *WebsiteHandler) GetConfigSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, h.websiteService.GetConfigSchema())
+}
+
+// PatchWebsiteConfig godoc
+// @Summary Patch website config
+// @Description Partially update the website configuration with a JSON Merge Patch (RFC 7396): members replace existing values and null removes them. The result is validated like a full config.
+// @Tags Websites
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Website ID"
+// @Param patch body object true "JSON Merge Patch for the config"
+// @Param If-Match header string false "ETag the website must still have; answers 412 Precondition Failed otherwise"
+// @Success 200 {object} map[string]models.Website "Website updated successfully"
+// @Failure 400 {object} models.Problem "Invalid patch or config validation errors"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Failure 412 {object} models.Problem "Website was modified since the ETag was read"
+// @Failure 415 {object} models.Problem "Unsupported patch format"
+// @Router /websites/{id}/config [patch]
+func (h *WebsiteHandler) PatchWebsiteConfig(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		utils.InvalidParameterResponse(c, "Invalid website ID")
 		return
 	}
 
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(c)
+	if !ok {
+		return
+	}
+
+	website, err := h.websiteService.PatchWebsiteConfig(id, patch, expectedVersion)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, website.Version)
 	c.JSON(http.StatusOK, gin.H{"website": website})
 }
 
 // DeleteWebsite godoc
 // @Summary Delete website
-// @Description Delete a website
+// @Description Move a website and its pages to the trash, where it can be restored until the trash retention period has passed, or with permanent=true delete it for good with every page, asset (including the stored files), link, tag and redirect in one transaction. Run with dryRun=true first: it reports what would be deleted and a confirmationToken that the deletion must pass back. The token stops matching when the website or its pages change.
 // @Tags Websites
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path int true "Website ID"
-// @Success 200 {object} map[string]string "Website deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid website ID"
-// @Failure 404 {object} map[string]string "Website not found"
+// @Param dryRun query bool false "Only report what would be deleted"
+// @Param permanent query bool false "Delete for good instead of moving to the trash"
+// @Param confirmationToken query string false "Token from the dry run; required unless dryRun is set"
+// @Param If-Match header string false "ETag the website must still have; answers 412 Precondition Failed otherwise"
+// @Success 200 {object} map[string]interface{} "Deletion report, with a message unless it was a dry run"
+// @Failure 400 {object} models.Problem "Invalid website ID or missing confirmation token"
+// @Failure 404 {object} models.Problem "Website not found"
+// @Failure 409 {object} models.Problem "Confirmation token does not match"
+// @Failure 412 {object} models.Problem "Website was modified since the ETag was read"
 // @Router /websites/{id} [delete]
 func (h *WebsiteHandler) DeleteWebsite(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid website ID"})
+		utils.InvalidParameterResponse(c, "Invalid website ID")
 		return
 	}
 
-	err = h.websiteService.DeleteWebsite(id)
+	var req models.DeleteWebsiteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.websiteService.DeleteWebsite(id, expectedVersion, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Website deleted successfully"})
+	if deletion.DryRun {
+		c.JSON(http.StatusOK, gin.H{"deletion": deletion})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Website deleted successfully", "deletion": deletion})
 }
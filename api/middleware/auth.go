@@ -1,58 +1,102 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
 	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
 )
 
+// passwordChangeRoutes are the routes open to users who must change their
+// password before doing anything else.
+var passwordChangeRoutes = map[string]bool{
+	"/auth/me":              true,
+	"/auth/change-password": true,
+}
+
+// twoFactorEnrollmentRoutes are the routes open to users whose role
+// requires two-factor authentication before they have enrolled.
+var twoFactorEnrollmentRoutes = map[string]bool{
+	"/auth/me":              true,
+	"/auth/change-password": true,
+	"/auth/2fa":             true,
+	"/auth/2fa/setup":       true,
+	"/auth/2fa/enable":      true,
+}
+
 type AuthMiddleware struct {
-	userService *service.UserService
+	userService      *service.UserService
+	apiTokenService  *service.APITokenService
+	twoFactorService *service.TwoFactorService
+	cookies          *SessionCookies
 }
 
-func NewAuthMiddleware(userService *service.UserService) *AuthMiddleware {
-	return &AuthMiddleware{userService: userService}
+func NewAuthMiddleware(userService *service.UserService, apiTokenService *service.APITokenService,
+	twoFactorService *service.TwoFactorService, cookies *SessionCookies) *AuthMiddleware {
+	return &AuthMiddleware{
+		userService:      userService,
+		apiTokenService:  apiTokenService,
+		twoFactorService: twoFactorService,
+		cookies:          cookies,
+	}
 }
 
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header required",
-			})
-			c.Abort()
+		var user *models.User
+		var err error
+
+		// Get token from Authorization header, or else the session cookie
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			// Extract token from "Bearer <token>" format
+			tokenParts := strings.Split(authHeader, " ")
+			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+				utils.UnauthorizedResponse(c, "Invalid authorization header format. Use 'Bearer <token>'")
+				return
+			}
+
+			// Validate session or API token
+			user, err = m.authenticate(c, tokenParts[1])
+		} else if token, ok := m.cookies.Token(c); ok {
+			user, err = m.authenticateCookie(c, token)
+		} else {
+			utils.UnauthorizedResponse(c, "Authorization header or session cookie required")
 			return
 		}
-
-		// Extract token from "Bearer <token>" format
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format. Use 'Bearer <token>'",
-			})
-			c.Abort()
+		if errors.Is(err, errCSRFFailed) {
+			utils.ProblemResponse(c, http.StatusForbidden, utils.CodeCSRFFailed,
+				"missing or invalid "+CSRFHeader+" header", nil)
 			return
 		}
-
-		token := tokenParts[1]
-
-		// Validate session token
-		user, err := m.userService.ValidateSession(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid or expired session token",
-			})
-			c.Abort()
+			utils.UnauthorizedResponse(c, "Invalid or expired session or API token")
 			return
 		}
 
-		// Store user in context for use in handlers
-		c.Set("user", user)
-		c.Set("user_id", user.ID)
+		// Users with a forced or default password may only change it
+		if user.MustChangePassword && !passwordChangeRoutes[c.FullPath()] {
+			utils.ProblemResponse(c, http.StatusForbidden, service.CodePasswordChangeRequired,
+				"password change required; use POST /auth/change-password", nil)
+			return
+		}
+
+		// Users whose role requires two-factor authentication must enroll
+		if !twoFactorEnrollmentRoutes[c.FullPath()] {
+			required, err := m.twoFactorService.EnrollmentRequired(user.ID)
+			if err != nil {
+				utils.InternalErrorResponse(c, err)
+				return
+			}
+			if required {
+				utils.ProblemResponse(c, http.StatusForbidden, service.CodeTwoFactorRequired,
+					"a role of yours requires two-factor authentication; enroll with POST /auth/2fa/setup", nil)
+				return
+			}
+		}
 
 		c.Next()
 	}
@@ -60,9 +104,12 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 
 func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get token from Authorization header
+		// Get token from Authorization header, or else the session cookie
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
+			if token, ok := m.cookies.Token(c); ok {
+				m.authenticateCookie(c, token)
+			}
 			c.Next()
 			return
 		}
@@ -76,16 +123,123 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 
 		token := tokenParts[1]
 
-		// Validate session token
-		user, err := m.userService.ValidateSession(token)
+		// Validate session or API token
+		m.authenticate(c, token)
+
+		c.Next()
+	}
+}
+
+// errCSRFFailed reports a cookie-authenticated request without a valid
+// CSRF token.
+var errCSRFFailed = errors.New("CSRF check failed")
+
+// authenticateCookie validates the session token of the session cookie.
+// Browsers send cookies on cross-site requests too, so mutating requests
+// must also carry the CSRF token, which other sites cannot read. The check
+// comes first, so a request failing it never sees the user.
+func (m *AuthMiddleware) authenticateCookie(c *gin.Context, token string) (*models.User, error) {
+	if service.IsAPIToken(token) {
+		return nil, service.ErrInvalidAPIToken
+	}
+	if !m.cookies.CheckCSRF(c, token) {
+		return nil, errCSRFFailed
+	}
+
+	user, err := m.authenticate(c, token)
+	if err != nil {
+		return nil, err
+	}
+	c.Set("cookie_auth", true)
+	return user, nil
+}
+
+// authenticate validates a session token or, by its prefix, a personal API
+// token and stores the user in the context for use in handlers. Requests
+// with an API token also get its ID and scopes, those with a session token
+// the session ID.
+func (m *AuthMiddleware) authenticate(c *gin.Context, token string) (*models.User, error) {
+	if service.IsAPIToken(token) {
+		user, apiToken, err := m.apiTokenService.ValidateToken(token, clientInfo(c))
 		if err != nil {
+			return nil, err
+		}
+		c.Set("user", user)
+		c.Set("user_id", user.ID)
+		c.Set("api_token_id", apiToken.ID)
+		c.Set("api_token_scopes", apiToken.Scopes)
+		return user, nil
+	}
+
+	user, session, err := m.userService.ValidateSession(token, clientInfo(c))
+	if err != nil {
+		return nil, err
+	}
+	c.Set("user", user)
+	c.Set("user_id", user.ID)
+	c.Set("session_id", session.ID)
+	return user, nil
+}
+
+// clientInfo describes the client of a request for the user service.
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// RequireScope limits API tokens to the routes of a resource their scopes
+// cover: safe methods need the read scope, all others the write scope.
+// Session tokens are not limited. It must run after RequireAuth.
+func (m *AuthMiddleware) RequireScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get("api_token_scopes")
+		if !ok {
 			c.Next()
 			return
 		}
 
-		// Store user in context for use in handlers
-		c.Set("user", user)
-		c.Set("user_id", user.ID)
+		write := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+		if !service.ScopesAllow(value.([]string), resource, write) {
+			scope := resource + ":read"
+			if write {
+				scope = resource + ":write"
+			}
+			utils.ProblemResponse(c, http.StatusForbidden, service.CodeInsufficientScope,
+				"the API token lacks the "+scope+" scope", gin.H{"requiredScope": scope})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession refuses API tokens, for account management that needs a
+// login session, such as changing the password or issuing tokens. It must
+// run after RequireAuth.
+func (m *AuthMiddleware) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_token_id"); ok {
+			utils.ProblemResponse(c, http.StatusForbidden, service.CodeForbidden,
+				"this endpoint requires a login session, not an API token", nil)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole allows only users with the named role. It must run after
+// RequireAuth.
+func (m *AuthMiddleware) RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, err := m.userService.HasRole(c.GetInt("user_id"), role)
+		if err != nil {
+			utils.InternalErrorResponse(c, err)
+			return
+		}
+		if !ok {
+			utils.ProblemResponse(c, http.StatusForbidden, service.CodeForbidden, "the "+role+" role is required", nil)
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+)
+
+// CSRFHeader carries the CSRF token on mutating requests authenticated by
+// the session cookie.
+const CSRFHeader = "X-CSRF-Token"
+
+// SessionCookies sets and reads the cookies of browser sessions: an
+// HttpOnly cookie with the session token and a cookie readable by scripts
+// with the CSRF token, which must be echoed in CSRFHeader. The cookies last
+// as long as a session can; the server enforces the idle timeout.
+type SessionCookies struct {
+	Name     string
+	CSRFName string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+	// MaxAge returns the current maximum session lifetime, a runtime setting
+	MaxAge func() time.Duration
+}
+
+// NewSessionCookies returns the session cookie settings. sameSite is
+// "strict", "lax" or "none".
+func NewSessionCookies(name, csrfName, domain string, secure bool, sameSite string,
+	maxAge func() time.Duration) (*SessionCookies, error) {
+	cookies := &SessionCookies{Name: name, CSRFName: csrfName, Domain: domain, Secure: secure, MaxAge: maxAge}
+	switch strings.ToLower(sameSite) {
+	case "strict":
+		cookies.SameSite = http.SameSiteStrictMode
+	case "lax", "":
+		cookies.SameSite = http.SameSiteLaxMode
+	case "none":
+		// Browsers drop SameSite=None cookies that are not Secure
+		if !secure {
+			return nil, fmt.Errorf("SameSite=None requires secure cookies")
+		}
+		cookies.SameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("unknown SameSite mode %q", sameSite)
+	}
+	return cookies, nil
+}
+
+// Set stores a new session in the cookies.
+func (sc *SessionCookies) Set(c *gin.Context, sessionToken string) {
+	expiresAt := time.Now().Add(sc.MaxAge())
+	sc.set(c, sc.Name, sessionToken, expiresAt, true)
+	sc.set(c, sc.CSRFName, service.CSRFToken(sessionToken), expiresAt, false)
+}
+
+// Clear removes the session cookies.
+func (sc *SessionCookies) Clear(c *gin.Context) {
+	sc.set(c, sc.Name, "", time.Unix(0, 0), true)
+	sc.set(c, sc.CSRFName, "", time.Unix(0, 0), false)
+}
+
+func (sc *SessionCookies) set(c *gin.Context, name, value string, expiresAt time.Time, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   sc.Domain,
+		Expires:  expiresAt,
+		Secure:   sc.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sc.SameSite,
+	})
+}
+
+// oidcStateSuffix names the cookie that binds a single sign-on login to
+// the browser that started it, after the session cookie
+const oidcStateSuffix = "_oidc_state"
+
+// SetOIDCState remembers the state of a single sign-on login for ttl. The
+// cookie is always SameSite=Lax, as it must come back with the identity
+// provider's cross-site redirect to the callback.
+func (sc *SessionCookies) SetOIDCState(c *gin.Context, state string, ttl time.Duration) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sc.Name + oidcStateSuffix,
+		Value:    state,
+		Path:     "/auth/oidc",
+		Domain:   sc.Domain,
+		Expires:  time.Now().Add(ttl),
+		Secure:   sc.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// CheckOIDCState reports whether state belongs to a login this browser
+// started, and forgets the login.
+func (sc *SessionCookies) CheckOIDCState(c *gin.Context, state string) bool {
+	cookieState, err := c.Cookie(sc.Name + oidcStateSuffix)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sc.Name + oidcStateSuffix,
+		Path:     "/auth/oidc",
+		Domain:   sc.Domain,
+		Expires:  time.Unix(0, 0),
+		Secure:   sc.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return err == nil && state != "" && subtle.ConstantTimeCompare([]byte(cookieState), []byte(state)) == 1
+}
+
+// Token returns the session token of the session cookie, if any.
+func (sc *SessionCookies) Token(c *gin.Context) (string, bool) {
+	token, err := c.Cookie(sc.Name)
+	if err != nil || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// CheckCSRF reports whether a request authenticated by the session cookie
+// may proceed: safe methods always may, others must send the CSRF token
+// of the session in CSRFHeader.
+func (sc *SessionCookies) CheckCSRF(c *gin.Context, sessionToken string) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	header := c.GetHeader(CSRFHeader)
+	expected := service.CSRFToken(sessionToken)
+	return header != "" && subtle.ConstantTimeCompare([]byte(header), []byte(expected)) == 1
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+// maintenanceMessage is the detail of refused requests when no message is
+// configured.
+const maintenanceMessage = "the API is in read-only maintenance mode"
+
+// MaintenanceMiddleware enforces the read-only maintenance mode.
+type MaintenanceMiddleware struct {
+	settingsService *service.SettingsService
+	userService     *service.UserService
+}
+
+func NewMaintenanceMiddleware(settingsService *service.SettingsService, userService *service.UserService) *MaintenanceMiddleware {
+	return &MaintenanceMiddleware{settingsService: settingsService, userService: userService}
+}
+
+// ReadOnly refuses requests other than GET, HEAD and OPTIONS with 503 and
+// a Retry-After header while maintenance mode is on. Admins may still
+// write; the user is known only when ReadOnly runs after RequireAuth.
+func (m *MaintenanceMiddleware) ReadOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		status := m.settingsService.Maintenance()
+		if !status.Enabled {
+			c.Next()
+			return
+		}
+
+		if userID := c.GetInt("user_id"); userID != 0 {
+			admin, err := m.userService.HasRole(userID, "admin")
+			if err != nil {
+				utils.InternalErrorResponse(c, err)
+				return
+			}
+			if admin {
+				c.Next()
+				return
+			}
+		}
+
+		detail := status.Message
+		if detail == "" {
+			detail = maintenanceMessage
+		}
+		retryAfter := int(math.Ceil(status.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		utils.ProblemResponse(c, http.StatusServiceUnavailable, service.CodeMaintenance, detail, gin.H{"retryAfter": retryAfter})
+	}
+}
@@ -1,54 +1,162 @@
 package routes
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 	"github.com/xeodocs/xeodocs-dash-api/api/handlers"
 	"github.com/xeodocs/xeodocs-dash-api/api/middleware"
+	"github.com/xeodocs/xeodocs-dash-api/config"
+	"github.com/xeodocs/xeodocs-dash-api/internal/jobs"
+	"github.com/xeodocs/xeodocs-dash-api/internal/mailer"
+	"github.com/xeodocs/xeodocs-dash-api/internal/oidc"
 	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
 	"github.com/xeodocs/xeodocs-dash-api/internal/service"
+	"github.com/xeodocs/xeodocs-dash-api/internal/settings"
+	"github.com/xeodocs/xeodocs-dash-api/internal/storage"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
 )
 
-func SetupRoutes(db *sql.DB) *gin.Engine {
+func SetupRoutes(db *sql.DB, cfg *config.Config, mail mailer.Mailer) *gin.Engine {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	websiteRepo := repository.NewWebsiteRepository(db)
 	pageRepo := repository.NewPageRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	redirectRepo := repository.NewRedirectRepository(db)
+	assetRepo := repository.NewAssetRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	userConfigRepo := repository.NewUserConfigRepository(db)
+	systemConfigRepo := repository.NewSystemConfigRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	// Initialize blob storage
+	blobStore := storage.NewLocalStore(cfg.StoragePath)
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
-	websiteService := service.NewWebsiteService(websiteRepo)
-	pageService := service.NewPageService(pageRepo, websiteRepo)
+	loginThrottle := service.NewLoginThrottle(loginThrottleRepo, txManager, service.LoginThrottleOptions{
+		MaxFailures:     cfg.LoginMaxFailures,
+		IPMaxFailures:   cfg.LoginIPMaxFailures,
+		LockoutDuration: cfg.LoginLockoutDuration,
+		BackoffBase:     cfg.LoginBackoffBase,
+		BackoffMax:      cfg.LoginBackoffMax,
+	})
+	settingsService, err := service.NewSettingsService(systemConfigRepo, userRepo, txManager, os.LookupEnv)
+	if err != nil {
+		log.Fatalf("Invalid system settings: %v", err)
+	}
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, txManager, cfg.TOTPIssuer)
+	userService := service.NewUserService(userRepo, apiTokenRepo, txManager, loginThrottle, twoFactorService, settingsService, mail, service.AuthOptions{
+		PasswordResetURL: cfg.PasswordResetURL,
+		PasswordResetTTL: cfg.PasswordResetTTL,
+
+		DisablePasswordLogin: !cfg.PasswordLoginEnabled,
+	})
+	invitationService := service.NewInvitationService(invitationRepo, userRepo, txManager, userService, mail, service.InvitationOptions{
+		URL: cfg.InviteURL,
+		TTL: cfg.InviteTTL,
+	})
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo, txManager)
+	preferenceService := service.NewPreferenceService(userConfigRepo, websiteRepo, txManager)
+	pageService := service.NewPageService(pageRepo, websiteRepo, linkRepo, redirectRepo, txManager, settingsService)
+	redirectService := service.NewRedirectService(redirectRepo, pageRepo, websiteRepo)
+	tagService := service.NewTagService(tagRepo, websiteRepo, txManager)
+	archiveService := service.NewArchiveService(websiteRepo, pageRepo, assetRepo, linkRepo, redirectRepo, txManager, blobStore)
+	trashService := service.NewTrashService(trashRepo, websiteRepo, pageRepo, userRepo, assetRepo, linkRepo, redirectRepo,
+		txManager, settingsService, blobStore)
+	websiteService := service.NewWebsiteService(websiteRepo, redirectRepo, txManager, trashService)
+
+	// Background jobs pause while the API is in maintenance mode
+	jobs.Start(context.Background(), func() bool { return settingsService.Maintenance().Enabled }, jobs.Job{
+		Name:     "trash purge",
+		Interval: cfg.TrashPurgeInterval,
+		Run: func() error {
+			_, err := trashService.PurgeExpired()
+			return err
+		},
+	})
+
+	// Browser sessions may use cookies instead of bearer tokens
+	sessionCookies, err := middleware.NewSessionCookies(cfg.SessionCookieName, cfg.CSRFCookieName,
+		cfg.SessionCookieDomain, cfg.SessionCookieSecure, cfg.SessionCookieSameSite, func() time.Duration {
+			return settingsService.Duration(settings.SessionMaxLifetime)
+		})
+	if err != nil {
+		log.Fatalf("Invalid session cookie settings: %v", err)
+	}
+
+	// Single sign-on is enabled by configuring an issuer
+	var oidcHandler *handlers.OIDCHandler
+	if cfg.OIDCIssuer != "" {
+		oidcHandler = handlers.NewOIDCHandler(newOIDCService(cfg, oidcRepo, userRepo, twoFactorRepo, txManager, userService),
+			sessionCookies, cfg.OIDCPostLoginURL)
+	}
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, preferenceService, sessionCookies)
+	preferenceHandler := handlers.NewPreferenceHandler(preferenceService)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService, sessionCookies)
 	websiteHandler := handlers.NewWebsiteHandler(websiteService)
 	pageHandler := handlers.NewPageHandler(pageService)
+	redirectHandler := handlers.NewRedirectHandler(redirectService)
+	archiveHandler := handlers.NewArchiveHandler(archiveService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	systemSettingsHandler := handlers.NewSystemSettingsHandler(settingsService)
+	trashHandler := handlers.NewTrashHandler(trashService)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(userService)
+	authMiddleware := middleware.NewAuthMiddleware(userService, apiTokenService, twoFactorService, sessionCookies)
+	maintenance := middleware.NewMaintenanceMiddleware(settingsService, userService)
 
 	// Setup Gin router
 	r := gin.Default()
 
+	// Only trust X-Forwarded-For from configured proxies, so clients cannot
+	// spoof the IP that login throttling counts
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Global middleware
 	r.Use(middleware.Logger())
-	r.Use(gin.Recovery())
+	r.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		utils.InternalErrorResponse(c, fmt.Errorf("panic: %v", recovered))
+	}))
+
+	// Unknown routes answer with the same problem details as handlers
+	r.NoRoute(func(c *gin.Context) {
+		utils.ProblemResponse(c, http.StatusNotFound, utils.CodeRouteNotFound,
+			fmt.Sprintf("no route for %s %s", c.Request.Method, c.Request.URL.Path), nil)
+	})
 
 	// Health check endpoint (no auth required)
 	// HealthCheck godoc
 	// @Summary Health check
-	// @Description Check if the API service is running
+	// @Description Check if the API service is running and whether it is in read-only maintenance mode
 	// @Tags Health
 	// @Accept json
 	// @Produce json
-	// @Success 200 {object} map[string]string "Service is healthy"
+	// @Success 200 {object} map[string]interface{} "Service is healthy"
 	// @Router /health [get]
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "UP"})
+		c.JSON(200, gin.H{"status": "UP", "maintenance": settingsService.Maintenance()})
 	})
 
 	// Swagger documentation endpoints
@@ -62,50 +170,171 @@ func SetupRoutes(db *sql.DB) *gin.Engine {
 		c.Redirect(302, "/swagger/index.html")
 	})
 
-	// Authentication routes (no auth required)
+	// Authentication routes (no auth required). Logins stay open during
+	// maintenance so admins can get in
 	auth := r.Group("/auth")
 	{
 		auth.POST("/login", userHandler.Login)
+		auth.POST("/login/2fa", userHandler.CompleteTwoFactorLogin)
 		auth.POST("/logout", userHandler.Logout)
 		auth.GET("/me", authMiddleware.RequireAuth(), userHandler.GetCurrentUser)
+		auth.POST("/password-reset", maintenance.ReadOnly(), userHandler.RequestPasswordReset)
+		auth.POST("/password-reset/confirm", maintenance.ReadOnly(), userHandler.ConfirmPasswordReset)
+		auth.POST("/accept-invite", maintenance.ReadOnly(), invitationHandler.AcceptInvitation)
+		if oidcHandler != nil {
+			auth.GET("/oidc/login", oidcHandler.Login)
+			auth.GET("/oidc/callback", oidcHandler.Callback)
+		}
+	}
+
+	// Account routes (require a login session; API tokens are refused)
+	account := auth.Group("")
+	account.Use(authMiddleware.RequireAuth(), authMiddleware.RequireSession(), maintenance.ReadOnly())
+	{
+		account.POST("/change-password", userHandler.ChangePassword)
+		account.GET("/sessions", userHandler.GetSessions)
+		account.DELETE("/sessions/:id", userHandler.RevokeSession)
+		account.POST("/sessions/revoke-others", userHandler.RevokeOtherSessions)
+		account.GET("/tokens", apiTokenHandler.GetTokens)
+		account.POST("/tokens", apiTokenHandler.CreateToken)
+		account.DELETE("/tokens/:id", apiTokenHandler.RevokeToken)
+		account.GET("/2fa", twoFactorHandler.GetStatus)
+		account.POST("/2fa/setup", twoFactorHandler.Setup)
+		account.POST("/2fa/enable", twoFactorHandler.Enable)
+		account.POST("/2fa/disable", twoFactorHandler.Disable)
+		account.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
 	}
 
 	// Protected routes (require authentication)
 	protected := r.Group("/")
-	protected.Use(authMiddleware.RequireAuth())
+	protected.Use(authMiddleware.RequireAuth(), maintenance.ReadOnly())
 	{
 		// User routes
-		users := protected.Group("/users")
+		users := protected.Group("/users", authMiddleware.RequireScope("users"))
 		{
 			users.GET("", userHandler.GetUsers)
+			users.GET("/me/preferences", preferenceHandler.GetPreferences)
+			users.PUT("/me/preferences", preferenceHandler.ReplacePreferences)
+			users.PATCH("/me/preferences", preferenceHandler.PatchPreferences)
 			users.GET("/:id", userHandler.GetUser)
-			users.POST("", userHandler.CreateUser)
+			users.POST("", authMiddleware.RequireRole("admin"), userHandler.CreateUser)
 			users.PUT("/:id", userHandler.UpdateUser)
+			users.PATCH("/:id", userHandler.PatchUser)
 			users.DELETE("/:id", userHandler.DeleteUser)
+			users.POST("/:id/force-password-reset", authMiddleware.RequireRole("admin"), userHandler.ForcePasswordReset)
+			users.POST("/:id/unlock", authMiddleware.RequireRole("admin"), userHandler.UnlockUser)
+			users.DELETE("/:id/sessions", authMiddleware.RequireRole("admin"), userHandler.RevokeUserSessions)
+			users.DELETE("/:id/2fa", authMiddleware.RequireRole("admin"), twoFactorHandler.ResetUserTwoFactor)
+		}
+
+		// Role routes
+		roles := protected.Group("/roles", authMiddleware.RequireScope("users"), authMiddleware.RequireRole("admin"))
+		{
+			roles.GET("", userHandler.GetRoles)
+			roles.PUT("/:id/2fa", twoFactorHandler.SetRoleRequirement)
+		}
+
+		// Invitation routes
+		invitations := protected.Group("/invitations", authMiddleware.RequireScope("users"), authMiddleware.RequireRole("admin"))
+		{
+			invitations.GET("", invitationHandler.GetInvitations)
+			invitations.POST("", invitationHandler.CreateInvitation)
+			invitations.POST("/:id/resend", invitationHandler.ResendInvitation)
+			invitations.DELETE("/:id", invitationHandler.RevokeInvitation)
+		}
+
+		// System settings routes
+		system := protected.Group("/system", authMiddleware.RequireScope("system"), authMiddleware.RequireRole("admin"))
+		{
+			system.GET("/settings", systemSettingsHandler.GetSettings)
+			system.PATCH("/settings", systemSettingsHandler.PatchSettings)
+		}
+
+		// Trash routes
+		trash := protected.Group("/trash", authMiddleware.RequireScope("trash"), authMiddleware.RequireRole("admin"))
+		{
+			trash.GET("", trashHandler.GetTrash)
+			trash.POST("/:type/:id/restore", trashHandler.RestoreItem)
 		}
 
 		// Website routes
-		websites := protected.Group("/websites")
+		websites := protected.Group("/websites", authMiddleware.RequireScope("websites"))
 		{
 			websites.GET("", websiteHandler.GetWebsites)
 			websites.GET("/:id", websiteHandler.GetWebsite)
 			websites.GET("/slug/:slug", websiteHandler.GetWebsiteBySlug)
+			websites.GET("/config-schema", websiteHandler.GetConfigSchema)
 			websites.POST("", websiteHandler.CreateWebsite)
+			websites.POST("/import", archiveHandler.ImportWebsite)
 			websites.PUT("/:id", websiteHandler.UpdateWebsite)
+			websites.PATCH("/:id", websiteHandler.PatchWebsite)
+			websites.PATCH("/:id/config", websiteHandler.PatchWebsiteConfig)
 			websites.DELETE("/:id", websiteHandler.DeleteWebsite)
+			websites.GET("/:id/broken-links", pageHandler.GetBrokenLinks)
+			websites.POST("/:id/links/rebuild", pageHandler.RebuildLinks)
+			websites.GET("/:id/redirects/export", redirectHandler.ExportRedirects)
+			websites.GET("/:id/export", archiveHandler.ExportWebsite)
+			websites.GET("/:id/tags", tagHandler.GetTags)
+			websites.POST("/:id/tags/rename", tagHandler.RenameTag)
+			websites.POST("/:id/tags/merge", tagHandler.MergeTags)
 		}
 
 		// Page routes
-		pages := protected.Group("/pages")
+		pages := protected.Group("/pages", authMiddleware.RequireScope("pages"))
 		{
 			pages.GET("", pageHandler.GetPages) // Supports ?websiteId=X query param
 			pages.GET("/:id", pageHandler.GetPage)
 			pages.GET("/slug/:slug", pageHandler.GetPageBySlug)
+			pages.GET("/:id/links", pageHandler.GetPageLinks)
 			pages.POST("", pageHandler.CreatePage)
+			pages.POST("/lint", pageHandler.LintPage)
+			pages.POST("/bulk", pageHandler.BulkPages)
 			pages.PUT("/:id", pageHandler.UpdatePage)
+			pages.PATCH("/:id", pageHandler.PatchPage)
 			pages.DELETE("/:id", pageHandler.DeletePage)
 		}
+
+		// Redirect routes
+		redirects := protected.Group("/redirects", authMiddleware.RequireScope("redirects"))
+		{
+			redirects.GET("", redirectHandler.GetRedirects) // Supports ?websiteId=X&resourceType=page|website
+			redirects.GET("/:id", redirectHandler.GetRedirect)
+			redirects.POST("", redirectHandler.CreateRedirect)
+			redirects.PUT("/:id", redirectHandler.UpdateRedirect)
+			redirects.DELETE("/:id", redirectHandler.DeleteRedirect)
+		}
 	}
 
 	return r
 }
+
+// newOIDCService sets up single sign-on from the configuration.
+func newOIDCService(cfg *config.Config, oidcRepo *repository.OIDCRepository, userRepo *repository.UserRepository,
+	twoFactorRepo *repository.TwoFactorRepository, txManager *repository.TxManager,
+	userService *service.UserService) *service.OIDCService {
+	scopes := cfg.OIDCScopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	roleMapping := make(map[string]string)
+	for _, pair := range cfg.OIDCRoleMapping {
+		group, role, ok := strings.Cut(pair, "=")
+		if !ok || group == "" || role == "" {
+			log.Fatalf("Invalid OIDC_ROLE_MAPPING entry %q; use group=role", pair)
+		}
+		roleMapping[group] = role
+	}
+
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:       cfg.OIDCIssuer,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		Scopes:       scopes,
+	})
+	return service.NewOIDCService(provider, oidcRepo, userRepo, twoFactorRepo, txManager, userService, service.OIDCOptions{
+		GroupsClaim: cfg.OIDCGroupsClaim,
+		RoleMapping: roleMapping,
+	})
+}
@@ -47,6 +47,9 @@ import (
 
 	"github.com/xeodocs/xeodocs-dash-api/api/routes"
 	"github.com/xeodocs/xeodocs-dash-api/config"
+	"github.com/xeodocs/xeodocs-dash-api/internal/mailer"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/service"
 	_ "github.com/xeodocs/xeodocs-dash-api/docs" // Import generated docs
 )
 
@@ -62,8 +65,28 @@ func main() {
 	}
 	defer db.Close()
 
+	// Refuse to serve production with the seeded admin password
+	defaultCredentials, err := service.DefaultCredentialsValid(repository.NewUserRepository(db))
+	if err != nil {
+		log.Fatalf("Failed to check default credentials: %v", err)
+	}
+	if defaultCredentials {
+		log.Printf("WARNING: the default admin account %s still accepts the documented password. "+
+			"Log in and change it with POST /auth/change-password.", service.DefaultAdminEmail)
+		if cfg.Environment == "prod" && !cfg.AllowDefaultCredentials {
+			log.Fatalf("Refusing to start in prod with default admin credentials. " +
+				"Set ALLOW_DEFAULT_CREDENTIALS=true once to log in and change the password.")
+		}
+	}
+
+	// Initialize mail delivery
+	mail, err := mailer.New(cfg.MailDriver, cfg.MailFrom, cfg.MailDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
 	// Setup routes
-	router := routes.SetupRoutes(db)
+	router := routes.SetupRoutes(db, cfg, mail)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Port)
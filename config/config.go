@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +15,68 @@ type Config struct {
 	DatabaseURL    string
 	TursoAuthToken string
 	Port           string
+	StoragePath    string
+
+	// Browser logins may keep the session token in an HttpOnly cookie
+	// instead of returning it; CSRFCookieName holds the matching CSRF token
+	SessionCookieName     string
+	CSRFCookieName        string
+	SessionCookieDomain   string
+	SessionCookieSecure   bool
+	SessionCookieSameSite string
+
+	// Single sign-on through an OpenID Connect issuer, enabled when
+	// OIDCIssuer is set. OIDCRoleMapping lists "group=role" pairs.
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       []string
+	OIDCGroupsClaim  string
+	OIDCRoleMapping  []string
+	OIDCPostLoginURL string
+
+	// PasswordLoginEnabled allows logins with the passwords in users; turn
+	// it off to require single sign-on
+	PasswordLoginEnabled bool
+
+	// TOTPIssuer names the service in authenticator apps
+	TOTPIssuer string
+
+	// Password reset links point to PasswordResetURL with the token in the
+	// "token" query parameter and expire after PasswordResetTTL
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+
+	// Invitation links point to InviteURL with the token in the "token"
+	// query parameter and expire after InviteTTL
+	InviteURL string
+	InviteTTL time.Duration
+
+	// TrashPurgeInterval is how often items past the trash retention are
+	// purged
+	TrashPurgeInterval time.Duration
+
+	// MailDriver selects the mailer: "log" or "file" (writes to MailDir)
+	MailDriver string
+	MailFrom   string
+	MailDir    string
+
+	// AllowDefaultCredentials lets prod start while the seeded admin account
+	// still has its well-known password, e.g. to log in once and change it
+	AllowDefaultCredentials bool
+
+	// Brute-force protection: failures before an account or a client IP is
+	// locked, for how long, and the backoff applied before that
+	LoginMaxFailures     int
+	LoginIPMaxFailures   int
+	LoginLockoutDuration time.Duration
+	LoginBackoffBase     time.Duration
+	LoginBackoffMax      time.Duration
+
+	// TrustedProxies lists the proxies whose X-Forwarded-For header is
+	// trusted for the client IP; none by default
+	TrustedProxies []string
 }
 
 func Load() *Config {
@@ -35,6 +100,48 @@ func Load() *Config {
 		DatabaseURL:    databaseURL,
 		TursoAuthToken: getEnv("TURSO_AUTH_TOKEN", ""),
 		Port:           getEnv("PORT", "8080"),
+		StoragePath:    getEnv("STORAGE_PATH", "./local/storage"),
+
+		SessionCookieName:     getEnv("SESSION_COOKIE_NAME", "xeodocs_session"),
+		CSRFCookieName:        getEnv("CSRF_COOKIE_NAME", "xeodocs_csrf"),
+		SessionCookieDomain:   getEnv("SESSION_COOKIE_DOMAIN", ""),
+		SessionCookieSecure:   getEnv("SESSION_COOKIE_SECURE", "true") != "false",
+		SessionCookieSameSite: getEnv("SESSION_COOKIE_SAMESITE", "lax"),
+
+		OIDCIssuer:       getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback"),
+		OIDCScopes:       getEnvList("OIDC_SCOPES"),
+		OIDCGroupsClaim:  getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCRoleMapping:  getEnvList("OIDC_ROLE_MAPPING"),
+		OIDCPostLoginURL: getEnv("OIDC_POST_LOGIN_URL", "http://localhost:3000/"),
+
+		PasswordLoginEnabled: getEnv("PASSWORD_LOGIN_ENABLED", "true") != "false",
+
+		TOTPIssuer: getEnv("TOTP_ISSUER", "XeoDocs"),
+
+		PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		PasswordResetTTL: getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+
+		InviteURL: getEnv("INVITE_URL", "http://localhost:3000/accept-invite"),
+		InviteTTL: getEnvDuration("INVITE_TTL", 7*24*time.Hour),
+
+		TrashPurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+
+		MailDriver: getEnv("MAIL_DRIVER", "log"),
+		MailFrom:   getEnv("MAIL_FROM", "XeoDocs <no-reply@xeodocs.com>"),
+		MailDir:    getEnv("MAIL_DIR", "./local/mail"),
+
+		AllowDefaultCredentials: getEnv("ALLOW_DEFAULT_CREDENTIALS", "false") == "true",
+
+		LoginMaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 10),
+		LoginIPMaxFailures:   getEnvInt("LOGIN_IP_MAX_FAILURES", 50),
+		LoginLockoutDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginBackoffBase:     getEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+		LoginBackoffMax:      getEnvDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
 	}
 }
 
@@ -44,3 +151,45 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration parses a duration such as "30m" from the environment,
+// falling back to defaultValue when it is unset or invalid.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getEnvInt parses a positive integer from the environment, falling back to
+// defaultValue when it is unset or invalid.
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvList splits a comma-separated environment variable, dropping empty
+// items.
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
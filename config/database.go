@@ -22,7 +22,12 @@ func InitDatabase(cfg *Config) (*sql.DB, error) {
 		// For development, use SQLite
 		log.Println("Connecting to SQLite database...")
 		dbPath := strings.TrimPrefix(cfg.DatabaseURL, "sqlite://")
-		db, err = sql.Open("sqlite3", dbPath)
+		// SQLite leaves foreign keys off unless each connection turns them on
+		separator := "?"
+		if strings.Contains(dbPath, "?") {
+			separator = "&"
+		}
+		db, err = sql.Open("sqlite3", dbPath+separator+"_foreign_keys=on")
 	}
 
 	if err != nil {
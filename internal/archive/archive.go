@@ -0,0 +1,172 @@
+// Package archive reads and writes the flat file archives used for website
+// export and import, as tar.gz or zip.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Supported archive formats
+const (
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+)
+
+// File is a regular file inside an archive. Name is slash separated and
+// relative to the archive root.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// ContentType returns the MIME type of an archive format.
+func ContentType(format string) string {
+	if format == FormatZip {
+		return "application/zip"
+	}
+	return "application/gzip"
+}
+
+// Write encodes files into w using the given format.
+func Write(w io.Writer, format string, files []File) error {
+	now := time.Now()
+
+	switch format {
+	case FormatTarGz:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		for _, file := range files {
+			header := &tar.Header{
+				Name:    file.Name,
+				Mode:    0o644,
+				Size:    int64(len(file.Data)),
+				ModTime: now,
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Name, err)
+			}
+			if _, err := tw.Write(file.Data); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Name, err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return fmt.Errorf("failed to finish archive: %w", err)
+		}
+		return gz.Close()
+
+	case FormatZip:
+		zw := zip.NewWriter(w)
+		for _, file := range files {
+			fw, err := zw.CreateHeader(&zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: now})
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Name, err)
+			}
+			if _, err := fw.Write(file.Data); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Name, err)
+			}
+		}
+		return zw.Close()
+
+	default:
+		return fmt.Errorf("unsupported archive format %s", format)
+	}
+}
+
+// Read decodes an archive, detecting tar.gz or zip from its leading bytes.
+// Directories are skipped. Reading stops with an error once the uncompressed
+// contents exceed maxSize bytes.
+func Read(data []byte, maxSize int64) ([]File, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return readTarGz(data, maxSize)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return readZip(data, maxSize)
+	default:
+		return nil, fmt.Errorf("archive must be a tar.gz or zip file")
+	}
+}
+
+func readTarGz(data []byte, maxSize int64) ([]File, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	var files []File
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		file, err := readEntry(header.Name, tr, maxSize-total)
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(file.Data))
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func readZip(data []byte, maxSize int64) ([]File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	var files []File
+	var total int64
+	for _, entry := range zr.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name, err)
+		}
+		file, err := readEntry(entry.Name, rc, maxSize-total)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(file.Data))
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// readEntry validates an entry name and reads at most limit bytes of it.
+func readEntry(name string, r io.Reader, limit int64) (File, error) {
+	clean := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return File{}, fmt.Errorf("archive entry %s escapes the archive root", name)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if int64(len(data)) > limit {
+		return File{}, fmt.Errorf("archive contents are too large")
+	}
+	return File{Name: clean, Data: data}, nil
+}
@@ -0,0 +1,45 @@
+// Package jobs runs background jobs at fixed intervals.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a background task run every Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// Start runs each job in its own goroutine, first after one interval, until
+// ctx is done. Runs are skipped while paused reports true, e.g. during
+// maintenance. Errors are logged; the job runs again at the next interval.
+func Start(ctx context.Context, paused func() bool, jobs ...Job) {
+	for _, job := range jobs {
+		go run(ctx, paused, job)
+	}
+}
+
+func run(ctx context.Context, paused func() bool, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if paused() {
+			log.Printf("Skipping job %s during maintenance", job.Name)
+			continue
+		}
+		if err := job.Run(); err != nil {
+			log.Printf("Job %s failed: %v", job.Name, err)
+		}
+	}
+}
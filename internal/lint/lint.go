@@ -0,0 +1,112 @@
+// Package lint runs a configurable set of rules over page Markdown and
+// reports structured issues. Rules are pluggable: anything implementing Rule
+// can be registered on a Linter.
+package lint
+
+import (
+	"sort"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/markdown"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+// Page is the input handed to every rule.
+type Page struct {
+	WebsiteID int
+	Slug      string
+	Doc       *markdown.Document
+}
+
+// Reporter records an issue at a 1-based line and column.
+type Reporter func(line, column int, message string)
+
+type Rule interface {
+	// Name identifies the rule in results and in the website lint configuration
+	Name() string
+	// DefaultSeverity applies when the website configuration does not override it
+	DefaultSeverity() string
+	Check(page *Page, report Reporter)
+}
+
+type Linter struct {
+	rules []Rule
+}
+
+func New(rules ...Rule) *Linter {
+	return &Linter{rules: rules}
+}
+
+// Register adds a rule, replacing any registered rule with the same name.
+func (l *Linter) Register(rule Rule) {
+	for i, existing := range l.rules {
+		if existing.Name() == rule.Name() {
+			l.rules[i] = rule
+			return
+		}
+	}
+	l.rules = append(l.rules, rule)
+}
+
+// RuleNames lists the names of all registered rules.
+func (l *Linter) RuleNames() []string {
+	names := make([]string, 0, len(l.rules))
+	for _, rule := range l.rules {
+		names = append(names, rule.Name())
+	}
+	return names
+}
+
+// Lint runs every enabled rule against the given Markdown content.
+func (l *Linter) Lint(websiteID int, slug, content string, cfg *models.LintConfig) *models.LintResult {
+	page := &Page{
+		WebsiteID: websiteID,
+		Slug:      slug,
+		Doc:       markdown.Parse(content),
+	}
+
+	result := &models.LintResult{Issues: []models.LintIssue{}}
+	for _, rule := range l.rules {
+		severity := rule.DefaultSeverity()
+		if cfg != nil {
+			if configured, ok := cfg.Rules[rule.Name()]; ok && ValidSeverity(configured) {
+				severity = configured
+			}
+		}
+		if severity == models.LintSeverityOff {
+			continue
+		}
+
+		name := rule.Name()
+		rule.Check(page, func(line, column int, message string) {
+			result.Issues = append(result.Issues, models.LintIssue{
+				Rule:     name,
+				Severity: severity,
+				Line:     line,
+				Column:   column,
+				Message:  message,
+			})
+			if severity == models.LintSeverityError {
+				result.Errors++
+			} else {
+				result.Warnings++
+			}
+		})
+	}
+
+	sort.SliceStable(result.Issues, func(i, j int) bool {
+		if result.Issues[i].Line != result.Issues[j].Line {
+			return result.Issues[i].Line < result.Issues[j].Line
+		}
+		return result.Issues[i].Column < result.Issues[j].Column
+	})
+
+	return result
+}
+
+func ValidSeverity(severity string) bool {
+	switch severity {
+	case models.LintSeverityOff, models.LintSeverityWarning, models.LintSeverityError:
+		return true
+	}
+	return false
+}
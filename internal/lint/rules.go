@@ -0,0 +1,122 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/markdown"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+// SlugExistsFunc reports whether a page with the given slug exists in a website.
+type SlugExistsFunc func(websiteID int, slug string) bool
+
+// DefaultRules returns the built-in rule set.
+func DefaultRules(slugExists SlugExistsFunc) []Rule {
+	return []Rule{
+		FrontMatterRule{},
+		CodeFenceRule{},
+		DuplicateHeadingRule{},
+		DeadLinkRule{SlugExists: slugExists},
+	}
+}
+
+// FrontMatterRule checks that a leading front matter block is terminated and
+// holds a YAML mapping.
+type FrontMatterRule struct{}
+
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func (FrontMatterRule) Name() string            { return "front-matter" }
+func (FrontMatterRule) DefaultSeverity() string { return models.LintSeverityError }
+
+func (FrontMatterRule) Check(page *Page, report Reporter) {
+	fm := page.Doc.FrontMatter
+	if fm == nil {
+		return
+	}
+	if fm.EndLine == 0 {
+		report(fm.StartLine, 1, "front matter is not terminated by a closing '---'")
+		return
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(fm.Raw), &values); err != nil {
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			report(fm.StartLine+line, 1, "invalid front matter: "+m[2])
+			return
+		}
+		report(fm.StartLine+1, 1, "invalid front matter: front matter must be a YAML mapping")
+	}
+}
+
+// CodeFenceRule reports fenced code blocks that are never closed.
+type CodeFenceRule struct{}
+
+func (CodeFenceRule) Name() string            { return "code-fences" }
+func (CodeFenceRule) DefaultSeverity() string { return models.LintSeverityError }
+
+func (CodeFenceRule) Check(page *Page, report Reporter) {
+	for _, fence := range page.Doc.Fences {
+		if fence.EndLine == 0 {
+			report(fence.StartLine, 1, fmt.Sprintf("code fence opened with %s is never closed", fence.Marker))
+		}
+	}
+}
+
+// DuplicateHeadingRule reports headings whose anchors collide with an earlier heading.
+type DuplicateHeadingRule struct{}
+
+func (DuplicateHeadingRule) Name() string            { return "duplicate-headings" }
+func (DuplicateHeadingRule) DefaultSeverity() string { return models.LintSeverityWarning }
+
+func (DuplicateHeadingRule) Check(page *Page, report Reporter) {
+	seen := make(map[string]int)
+	for _, heading := range page.Doc.Headings {
+		if heading.Anchor == "" {
+			continue
+		}
+		if first, ok := seen[heading.Anchor]; ok {
+			report(heading.Line, heading.Column, fmt.Sprintf("duplicate heading %q (first defined on line %d)", heading.Text, first))
+			continue
+		}
+		seen[heading.Anchor] = heading.Line
+	}
+}
+
+// DeadLinkRule reports internal links to page slugs that do not exist in the website.
+type DeadLinkRule struct {
+	SlugExists SlugExistsFunc
+}
+
+func (DeadLinkRule) Name() string            { return "dead-links" }
+func (DeadLinkRule) DefaultSeverity() string { return models.LintSeverityError }
+
+func (r DeadLinkRule) Check(page *Page, report Reporter) {
+	if r.SlugExists == nil {
+		return
+	}
+
+	known := map[string]bool{page.Slug: true}
+	for _, link := range page.Doc.Links {
+		if link.Image {
+			continue
+		}
+		slug, ok := markdown.SlugFromTarget(link.Target)
+		if !ok {
+			continue
+		}
+		exists, checked := known[slug]
+		if !checked {
+			exists = r.SlugExists(page.WebsiteID, slug)
+			known[slug] = exists
+		}
+		if !exists {
+			report(link.Line, link.Column, fmt.Sprintf("link to unknown page %q", slug))
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Package markdown implements the small, line-oriented subset of Markdown
+// parsing the dashboard needs: front matter, fenced code blocks, ATX headings
+// and links. It is not a renderer; it only locates constructs and reports
+// their line and column so they can be linted and indexed.
+package markdown
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type FrontMatter struct {
+	Raw       string // YAML between the delimiters
+	StartLine int    // line of the opening delimiter
+	EndLine   int    // line of the closing delimiter, 0 when unterminated
+}
+
+type Fence struct {
+	Marker    string // the opening run of backticks or tildes
+	Info      string
+	StartLine int
+	EndLine   int // 0 when the fence is never closed
+}
+
+type Heading struct {
+	Level  int
+	Text   string
+	Anchor string
+	Line   int
+	Column int
+}
+
+type Link struct {
+	Text   string
+	Target string
+	Image  bool
+	Line   int
+	Column int
+
+	// byte offsets of the target within its line
+	targetStart int
+	targetEnd   int
+}
+
+// Document is the result of scanning a Markdown string. Line and column
+// numbers are 1-based and relative to the whole content, front matter included.
+type Document struct {
+	Lines       []string
+	FrontMatter *FrontMatter
+	Fences      []Fence
+	Headings    []Heading
+	Links       []Link
+}
+
+var (
+	atxHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fenceRe      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+	inlineLinkRe = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\[\]]*\])*)\]\(\s*(<[^>]*>|[^\s()]*(?:\([^\s()]*\)[^\s()]*)*)(?:\s+(?:"[^"]*"|'[^']*'|\([^)]*\)))?\s*\)`)
+	refDefRe     = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*(<[^>]*>|\S+)`)
+	codeSpanRe   = regexp.MustCompile("`+[^`]*`+")
+)
+
+// Parse scans content and returns its structure.
+func Parse(content string) *Document {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	doc := &Document{Lines: strings.Split(content, "\n")}
+
+	start := 0
+	if fm := parseFrontMatter(doc.Lines); fm != nil {
+		doc.FrontMatter = fm
+		if fm.EndLine == 0 {
+			// Unterminated front matter swallows the document; there is nothing else to scan
+			return doc
+		}
+		start = fm.EndLine
+	}
+
+	var open *Fence
+	for i := start; i < len(doc.Lines); i++ {
+		line := doc.Lines[i]
+		lineNo := i + 1
+
+		m := fenceRe.FindStringSubmatch(line)
+		if m != nil && m[1][0] == '`' && strings.Contains(m[2], "`") {
+			// Backtick fences may not carry backticks in their info string
+			m = nil
+		}
+		if m != nil {
+			if open == nil {
+				open = &Fence{Marker: m[1], Info: strings.TrimSpace(m[2]), StartLine: lineNo}
+				continue
+			}
+			if m[1][0] == open.Marker[0] && len(m[1]) >= len(open.Marker) && strings.TrimSpace(m[2]) == "" {
+				open.EndLine = lineNo
+				doc.Fences = append(doc.Fences, *open)
+				open = nil
+				continue
+			}
+		}
+		if open != nil {
+			continue
+		}
+
+		if m := atxHeadingRe.FindStringSubmatchIndex(line); m != nil {
+			text := ""
+			if m[4] >= 0 {
+				text = strings.TrimSpace(line[m[4]:m[5]])
+			}
+			doc.Headings = append(doc.Headings, Heading{
+				Level:  m[3] - m[2],
+				Text:   text,
+				Anchor: Anchor(text),
+				Line:   lineNo,
+				Column: runeColumn(line, m[2]+1),
+			})
+		}
+		doc.Links = append(doc.Links, scanLinks(line, lineNo)...)
+	}
+	if open != nil {
+		doc.Fences = append(doc.Fences, *open)
+	}
+
+	return doc
+}
+
+func parseFrontMatter(lines []string) *FrontMatter {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t") != "---" {
+		return nil
+	}
+
+	fm := &FrontMatter{StartLine: 1}
+	for i := 1; i < len(lines); i++ {
+		delim := strings.TrimRight(lines[i], " \t")
+		if delim == "---" || delim == "..." {
+			fm.Raw = strings.Join(lines[1:i], "\n")
+			fm.EndLine = i + 1
+			return fm
+		}
+	}
+	fm.Raw = strings.Join(lines[1:], "\n")
+	return fm
+}
+
+func scanLinks(line string, lineNo int) []Link {
+	// Blank out code spans so their contents are not mistaken for links,
+	// keeping byte offsets intact for column reporting
+	masked := codeSpanRe.ReplaceAllStringFunc(line, func(s string) string {
+		return strings.Repeat(" ", len(s))
+	})
+
+	var links []Link
+	if m := refDefRe.FindStringSubmatchIndex(masked); m != nil {
+		start, end := targetBounds(line, m[4], m[5])
+		links = append(links, Link{
+			Text:        line[m[2]:m[3]],
+			Target:      line[start:end],
+			Line:        lineNo,
+			Column:      runeColumn(line, m[4]+1),
+			targetStart: start,
+			targetEnd:   end,
+		})
+		return links
+	}
+
+	for _, m := range inlineLinkRe.FindAllStringSubmatchIndex(masked, -1) {
+		start, end := targetBounds(line, m[6], m[7])
+		links = append(links, Link{
+			Text:        line[m[4]:m[5]],
+			Target:      line[start:end],
+			Image:       m[3] > m[2],
+			Line:        lineNo,
+			Column:      runeColumn(line, m[0]+1),
+			targetStart: start,
+			targetEnd:   end,
+		})
+	}
+	return links
+}
+
+// targetBounds strips the optional angle brackets around a link destination.
+func targetBounds(line string, start, end int) (int, int) {
+	if end-start >= 2 && line[start] == '<' && line[end-1] == '>' {
+		return start + 1, end - 1
+	}
+	return start, end
+}
+
+// RewriteLinks calls rewrite for every link in content and replaces the
+// link target with the returned value when rewrite reports true. It returns
+// the new content and the number of targets replaced.
+func RewriteLinks(content string, rewrite func(link Link) (string, bool)) (string, int) {
+	doc := Parse(content)
+
+	replaced := 0
+	for i := len(doc.Links) - 1; i >= 0; i-- {
+		// Walk backwards so earlier offsets on the same line stay valid
+		link := doc.Links[i]
+		target, ok := rewrite(link)
+		if !ok || target == link.Target {
+			continue
+		}
+		line := doc.Lines[link.Line-1]
+		doc.Lines[link.Line-1] = line[:link.targetStart] + target + line[link.targetEnd:]
+		replaced++
+	}
+	if replaced == 0 {
+		return content, 0
+	}
+
+	return strings.Join(doc.Lines, "\n"), replaced
+}
+
+// ReplaceTargetSlug swaps the slug in a page link target for another one,
+// keeping any relative prefix, extension, query and fragment intact.
+func ReplaceTargetSlug(target, oldSlug, newSlug string) string {
+	rest := trimLinkPrefix(target)
+	if !strings.HasPrefix(rest, oldSlug) {
+		return target
+	}
+	prefix := target[:len(target)-len(rest)]
+	return prefix + newSlug + rest[len(oldSlug):]
+}
+
+func trimLinkPrefix(target string) string {
+	for {
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(target, "./"), "../"), "/")
+		if trimmed == target {
+			return target
+		}
+		target = trimmed
+	}
+}
+
+// Anchor returns the fragment identifier generated for a heading, following
+// the GitHub convention: lower-cased, punctuation removed, spaces as hyphens.
+func Anchor(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// SlugFromTarget reports the page slug a link target refers to when the
+// target is an internal page link such as "install", "./install.md" or
+// "/install#usage". External URLs, in-page anchors and asset paths are not
+// page links.
+func SlugFromTarget(target string) (string, bool) {
+	target = strings.TrimSpace(target)
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "//") {
+		return "", false
+	}
+	if i := strings.IndexAny(target, ":/"); i >= 0 && target[i] == ':' {
+		// Has a scheme (http:, mailto:, ...)
+		return "", false
+	}
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+
+	target = strings.TrimSuffix(trimLinkPrefix(target), "/")
+
+	switch path.Ext(target) {
+	case "":
+	case ".md", ".mdx", ".html":
+		target = strings.TrimSuffix(target, path.Ext(target))
+	default:
+		return "", false
+	}
+
+	if target == "" {
+		return "", false
+	}
+	return target, true
+}
+
+func runeColumn(line string, byteColumn int) int {
+	if byteColumn-1 > len(line) {
+		return byteColumn
+	}
+	return len([]rune(line[:byteColumn-1])) + 1
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// APIToken is a long-lived personal access token for automation such as CI
+// pipelines. Only the SHA-256 hash of the token is stored; TokenPrefix
+// holds its first characters so users can tell their tokens apart.
+type APIToken struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"userId" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenPrefix string     `json:"tokenPrefix" db:"token_prefix"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   *time.Time `json:"expiresAt" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	LastUsedIP  string     `json:"lastUsedIp" db:"last_used_ip"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// API token scopes. A write scope includes the matching read scope.
+const (
+	ScopeUsersRead      = "users:read"
+	ScopeUsersWrite     = "users:write"
+	ScopeWebsitesRead   = "websites:read"
+	ScopeWebsitesWrite  = "websites:write"
+	ScopePagesRead      = "pages:read"
+	ScopePagesWrite     = "pages:write"
+	ScopeRedirectsRead  = "redirects:read"
+	ScopeRedirectsWrite = "redirects:write"
+	ScopeSystemRead     = "system:read"
+	ScopeSystemWrite    = "system:write"
+	ScopeTrashRead      = "trash:read"
+	ScopeTrashWrite     = "trash:write"
+)
+
+// Request/Response DTOs
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=users:read users:write websites:read websites:write pages:read pages:write redirects:read redirects:write system:read system:write trash:read trash:write"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreateAPITokenResponse carries the new token. The token itself is shown
+// only in this response.
+type CreateAPITokenResponse struct {
+	APIToken APIToken `json:"apiToken"`
+	Token    string   `json:"token"`
+}
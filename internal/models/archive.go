@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ArchiveFormatVersion is the layout version written to website.json.
+const ArchiveFormatVersion = 1
+
+// Import conflict strategies
+const (
+	ImportConflictFail      = "fail"
+	ImportConflictRename    = "rename"
+	ImportConflictOverwrite = "overwrite"
+)
+
+// ArchiveManifest is stored as website.json at the root of an export.
+type ArchiveManifest struct {
+	FormatVersion int            `json:"formatVersion"`
+	ExportedAt    time.Time      `json:"exportedAt"`
+	Website       ArchiveWebsite `json:"website"`
+	Pages         []string       `json:"pages"`
+	MissingAssets []string       `json:"missingAssets,omitempty"`
+}
+
+// ArchiveWebsite is the exported website metadata. The git API token is a
+// secret and is never exported.
+type ArchiveWebsite struct {
+	Name          string          `json:"name"`
+	Slug          string          `json:"slug"`
+	Description   string          `json:"description"`
+	Slogan        string          `json:"slogan"`
+	Domain        string          `json:"domain"`
+	GitRepoOwner  string          `json:"gitRepoOwner"`
+	GitRepoName   string          `json:"gitRepoName"`
+	GitRepoBranch string          `json:"gitRepoBranch"`
+	Config        json.RawMessage `json:"config"`
+	LanguageCode  string          `json:"languageCode"`
+}
+
+// ArchivePage is the YAML front matter of an exported page file; the page
+// Markdown follows it unchanged.
+type ArchivePage struct {
+	Title              string         `yaml:"title"`
+	Slug               string         `yaml:"slug"`
+	Description        string         `yaml:"description"`
+	Status             string         `yaml:"status"`
+	FreezeStatus       bool           `yaml:"freezeStatus"`
+	Tags               []string       `yaml:"tags"`
+	ScheduledPublishAt *time.Time     `yaml:"scheduledPublishAt,omitempty"`
+	Assets             []ArchiveAsset `yaml:"assets,omitempty"`
+}
+
+// ArchiveAsset points from a page to an asset file inside the archive.
+type ArchiveAsset struct {
+	File      string `yaml:"file"`
+	MimeType  string `yaml:"mimeType"`
+	BucketKey string `yaml:"bucketKey"`
+}
+
+type ImportWebsiteRequest struct {
+	OnConflict  string `form:"onConflict" binding:"omitempty,oneof=fail rename overwrite"`
+	GitAPIToken string `form:"gitApiToken"`
+}
+
+// ImportReport summarizes a website import.
+type ImportReport struct {
+	Website        *Website          `json:"website"`
+	Overwritten    bool              `json:"overwritten"`
+	PagesCreated   int               `json:"pagesCreated"`
+	PagesUpdated   int               `json:"pagesUpdated"`
+	AssetsImported int               `json:"assetsImported"`
+	RenamedSlugs   map[string]string `json:"renamedSlugs,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
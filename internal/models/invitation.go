@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// Invitation is a pending invitation of an email address to join with a
+// role. Only the SHA-256 hash of the invite token is stored; the token is
+// mailed to the invitee and the invitation is deleted once accepted.
+type Invitation struct {
+	ID        int       `json:"id" db:"id"`
+	Email     string    `json:"email" db:"email"`
+	RoleID    int       `json:"roleId" db:"role_id"`
+	Role      string    `json:"role" db:"role"`
+	InvitedBy *int      `json:"invitedBy" db:"invited_by"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	SentAt    time.Time `json:"sentAt" db:"sent_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Request/Response DTOs
+type CreateInvitationRequest struct {
+	Email  string `json:"email" binding:"required,email"`
+	RoleID int    `json:"roleId" binding:"required"`
+}
+
+// AcceptInvitationRequest creates the account of an invitee. With
+// UseCookie the new session is set in cookies, as for LoginRequest.
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	Password  string `json:"password" binding:"required,min=6"`
+	UseCookie bool   `json:"useCookie"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// PageLink is an internal link from one page to another page slug in the same website.
+type PageLink struct {
+	ID           int       `json:"id" db:"id"`
+	WebsiteID    int       `json:"websiteId" db:"website_id"`
+	SourcePageID int       `json:"sourcePageId" db:"source_page_id"`
+	TargetSlug   string    `json:"targetSlug" db:"target_slug"`
+	LinkText     string    `json:"linkText" db:"link_text"`
+	Line         int       `json:"line" db:"line_number"`
+	Column       int       `json:"column" db:"column_number"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// PageLinkDetail is a link joined with the pages on both ends.
+type PageLinkDetail struct {
+	PageLink
+	SourceSlug   string  `json:"sourceSlug"`
+	SourceTitle  string  `json:"sourceTitle"`
+	TargetPageID *int    `json:"targetPageId"`
+	TargetTitle  *string `json:"targetTitle"`
+	Broken       bool    `json:"broken"`
+}
+
+type PageRef struct {
+	ID    int    `json:"id"`
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	Links int    `json:"links"`
+}
+
+// SlugChange describes the effect of renaming a page slug on the pages linking to it.
+type SlugChange struct {
+	OldSlug        string    `json:"oldSlug"`
+	NewSlug        string    `json:"newSlug"`
+	ReferringPages []PageRef `json:"referringPages"`
+	LinksRewritten bool      `json:"linksRewritten"`
+}
+
+// PageSaveResult carries information produced while saving a page.
+type PageSaveResult struct {
+	Lint       *LintResult `json:"lint"`
+	SlugChange *SlugChange `json:"slugChange,omitempty"`
+}
+
+// Request/Response DTOs
+type PageLinksResponse struct {
+	Outbound []*PageLinkDetail `json:"outbound"`
+	Inbound  []*PageLinkDetail `json:"inbound"`
+}
+
+type BrokenLinkReport struct {
+	WebsiteID   int               `json:"websiteId"`
+	TotalLinks  int               `json:"totalLinks"`
+	BrokenLinks int               `json:"brokenLinks"`
+	Links       []*PageLinkDetail `json:"links"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// Lint severities. A rule configured as "off" is not run at all.
+const (
+	LintSeverityOff     = "off"
+	LintSeverityWarning = "warning"
+	LintSeverityError   = "error"
+)
+
+type LintIssue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+}
+
+type LintResult struct {
+	Issues   []LintIssue `json:"issues"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+}
+
+// LintConfig is read from the "lint" key of Website.Config.
+type LintConfig struct {
+	// Rules overrides the severity of individual rules ("off", "warning" or "error")
+	Rules map[string]string `json:"rules,omitempty" validate:"omitempty,dive,oneof=off warning error" description:"Severity per rule name: off, warning or error"`
+	// BlockPublishOnError prevents pages with lint errors from being published (default true)
+	BlockPublishOnError *bool `json:"blockPublishOnError,omitempty" description:"Block publishing pages with lint errors (default true)"`
+}
+
+// Request/Response DTOs
+type LintPageRequest struct {
+	WebsiteID       int    `json:"websiteId" binding:"required"`
+	PageID          int    `json:"pageId"`
+	Slug            string `json:"slug"`
+	MarkdownContent string `json:"markdownContent" binding:"required"`
+}
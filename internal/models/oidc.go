@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// OIDCLoginState is a single sign-on login in progress. Only the SHA-256
+// hash of the state parameter is stored; the nonce and PKCE code verifier
+// are needed to finish the login.
+type OIDCLoginState struct {
+	ID           int       `json:"id" db:"id"`
+	StateHash    string    `json:"-" db:"state_hash"`
+	Nonce        string    `json:"-" db:"nonce"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	ExpiresAt    time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// UserIdentity links a user to an account at an identity provider, named
+// by the issuer and the subject of its ID tokens.
+type UserIdentity struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"userId" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	LastLoginAt *time.Time `json:"lastLoginAt" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
@@ -11,13 +11,14 @@ type Page struct {
 	Slug                 string     `json:"slug" db:"slug"`
 	Description          string     `json:"description" db:"description"`
 	MarkdownContent      string     `json:"markdownContent" db:"markdown_content"`
-	Tags                 string     `json:"tags" db:"tags"`
+	Tags                 []string   `json:"tags"`
 	FreezeStatus         bool       `json:"freezeStatus" db:"freeze_status"`
 	Status               string     `json:"status" db:"status"`
 	LastStatusChangeAt   time.Time  `json:"lastStatusChangeAt" db:"last_status_change_at"`
 	ScheduledPublishAt   *time.Time `json:"scheduledPublishAt" db:"scheduled_publish_at"`
 	CreatedAt            time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt            time.Time  `json:"updatedAt" db:"updated_at"`
+	Version              int        `json:"version" db:"version"`
 }
 
 type PageAsset struct {
@@ -34,11 +35,11 @@ type CreatePageRequest struct {
 	WebsiteID           int        `json:"websiteId" binding:"required"`
 	Title               string     `json:"title" binding:"required"`
 	Slug                string     `json:"slug" binding:"required"`
-	Description         string     `json:"description" binding:"required"`
+	Description         string     `json:"description"`
 	MarkdownContent     string     `json:"markdownContent" binding:"required"`
-	Tags                string     `json:"tags" binding:"omitempty"`
+	Tags                []string   `json:"tags" binding:"omitempty,dive,max=64"`
 	FreezeStatus        bool       `json:"freezeStatus"`
-	Status              string     `json:"status" binding:"required,oneof=draft translating translated ignored published"`
+	Status              string     `json:"status" binding:"omitempty,oneof=draft translating translated ignored published"`
 	ScheduledPublishAt  *time.Time `json:"scheduledPublishAt"`
 }
 
@@ -47,8 +48,66 @@ type UpdatePageRequest struct {
 	Slug                string     `json:"slug" binding:"omitempty"`
 	Description         string     `json:"description" binding:"omitempty"`
 	MarkdownContent     string     `json:"markdownContent" binding:"omitempty"`
-	Tags                string     `json:"tags" binding:"omitempty"`
+	// Tags replaces the page tags when present; an empty list clears them
+	Tags                []string   `json:"tags" binding:"omitempty,dive,max=64"`
 	FreezeStatus        *bool      `json:"freezeStatus"`
 	Status              string     `json:"status" binding:"omitempty,oneof=draft translating translated ignored published"`
 	ScheduledPublishAt  *time.Time `json:"scheduledPublishAt"`
+	// RewriteLinks updates links in other pages when the slug changes
+	RewriteLinks        bool       `json:"rewriteLinks"`
+}
+
+// Bulk page operations
+const (
+	BulkOpSetStatus       = "setStatus"
+	BulkOpFreeze          = "freeze"
+	BulkOpUnfreeze        = "unfreeze"
+	BulkOpAddTags         = "addTags"
+	BulkOpRemoveTags      = "removeTags"
+	BulkOpSchedulePublish = "schedulePublish"
+	BulkOpDelete          = "delete"
+)
+
+// PageFilter selects pages by their attributes; zero values are ignored.
+type PageFilter struct {
+	WebsiteID    int    `json:"websiteId"`
+	Status       string `json:"status" binding:"omitempty,oneof=draft translating translated ignored published"`
+	FreezeStatus *bool  `json:"freezeStatus"`
+	Tag          string `json:"tag"`
+}
+
+type BulkPageRequest struct {
+	Operation          string      `json:"operation" binding:"required,oneof=setStatus freeze unfreeze addTags removeTags schedulePublish delete"`
+	IDs                []int       `json:"ids"`
+	Filter             *PageFilter `json:"filter"`
+	Status             string      `json:"status" binding:"omitempty,oneof=draft translating translated ignored published"`
+	Tags               []string    `json:"tags" binding:"omitempty,dive,max=64"`
+	ScheduledPublishAt *time.Time  `json:"scheduledPublishAt"`
+	DryRun             bool        `json:"dryRun"`
+}
+
+// Bulk item outcomes
+const (
+	BulkResultUpdated   = "updated"
+	BulkResultTrashed   = "trashed"
+	BulkResultUnchanged = "unchanged"
+	BulkResultFailed    = "failed"
+)
+
+type BulkPageItemResult struct {
+	ID      int      `json:"id"`
+	Slug    string   `json:"slug,omitempty"`
+	Result  string   `json:"result"`
+	Changes []string `json:"changes,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type BulkPageResponse struct {
+	Operation string               `json:"operation"`
+	DryRun    bool                 `json:"dryRun"`
+	Applied   bool                 `json:"applied"`
+	Matched   int                  `json:"matched"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []BulkPageItemResult `json:"results"`
 }
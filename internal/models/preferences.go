@@ -0,0 +1,37 @@
+package models
+
+// UserPreferences are the settings of a user for the dashboard, stored in
+// user_config with one row per top-level key and its value as JSON. The
+// validate tags are enforced on every write.
+type UserPreferences struct {
+	Language         string                   `json:"language,omitempty" validate:"omitempty,max=35,bcp47_language_tag"`
+	Timezone         string                   `json:"timezone,omitempty" validate:"omitempty,max=64,timezone"`
+	Theme            string                   `json:"theme,omitempty" validate:"omitempty,oneof=auto light dark"`
+	DefaultWebsiteID *int                     `json:"defaultWebsiteId,omitempty" validate:"omitempty,min=1"`
+	Notifications    *NotificationPreferences `json:"notifications,omitempty"`
+	Editor           *EditorPreferences       `json:"editor,omitempty"`
+}
+
+// NotificationPreferences choose which notifications a user receives.
+// Unset flags mean the default, which is on.
+type NotificationPreferences struct {
+	Email    *bool  `json:"email,omitempty"`
+	Mentions *bool  `json:"mentions,omitempty"`
+	Reviews  *bool  `json:"reviews,omitempty"`
+	Digest   string `json:"digest,omitempty" validate:"omitempty,oneof=none daily weekly"`
+}
+
+type EditorPreferences struct {
+	Mode       string `json:"mode,omitempty" validate:"omitempty,oneof=markdown wysiwyg split"`
+	FontSize   int    `json:"fontSize,omitempty" validate:"omitempty,min=10,max=32"`
+	TabSize    int    `json:"tabSize,omitempty" validate:"omitempty,oneof=2 4 8"`
+	LineWrap   *bool  `json:"lineWrap,omitempty"`
+	SpellCheck *bool  `json:"spellCheck,omitempty"`
+	Vim        *bool  `json:"vim,omitempty"`
+}
+
+// CurrentUserResponse is the response of GET /auth/me.
+type CurrentUserResponse struct {
+	User        *User            `json:"user"`
+	Preferences *UserPreferences `json:"preferences"`
+}
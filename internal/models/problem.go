@@ -0,0 +1,23 @@
+package models
+
+// Problem is the RFC 7807 problem details body of every error response,
+// served as application/problem+json. Code is a stable, machine-readable
+// identifier of the failure; Errors lists the invalid fields of a request.
+// Some problems carry extra members, e.g. "lint" for lint failures.
+type Problem struct {
+	Type     string       `json:"type" example:"about:blank"`
+	Title    string       `json:"title" example:"Not Found"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail,omitempty" example:"page not found"`
+	Instance string       `json:"instance,omitempty" example:"/pages/42"`
+	Code     string       `json:"code" example:"not_found"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError reports a validation failure of a single request field. Code
+// names the failed rule, e.g. "required" or "unknown_field".
+type FieldError struct {
+	Field   string `json:"field" example:"title"`
+	Code    string `json:"code" example:"required"`
+	Message string `json:"message" example:"is required"`
+}
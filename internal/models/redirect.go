@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// Redirect resource types
+const (
+	RedirectTypePage    = "page"
+	RedirectTypeWebsite = "website"
+)
+
+// Redirect sends lookups of an old slug to the resource that now owns it.
+// Automatic redirects are created when a slug is renamed; manual ones are
+// managed through the API.
+type Redirect struct {
+	ID           int       `json:"id" db:"id"`
+	ResourceType string    `json:"resourceType" db:"resource_type"`
+	WebsiteID    int       `json:"websiteId" db:"website_id"`
+	OldSlug      string    `json:"oldSlug" db:"old_slug"`
+	TargetID     int       `json:"targetId" db:"target_id"`
+	TargetSlug   string    `json:"targetSlug"`
+	StatusCode   int       `json:"statusCode" db:"status_code"`
+	IsManual     bool      `json:"isManual" db:"is_manual"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Request/Response DTOs
+type CreateRedirectRequest struct {
+	ResourceType string `json:"resourceType" binding:"required,oneof=page website"`
+	OldSlug      string `json:"oldSlug" binding:"required"`
+	TargetID     int    `json:"targetId" binding:"required"`
+	StatusCode   int    `json:"statusCode" binding:"omitempty,oneof=301 302 307 308"`
+}
+
+type UpdateRedirectRequest struct {
+	OldSlug    string `json:"oldSlug" binding:"omitempty"`
+	TargetID   int    `json:"targetId" binding:"omitempty"`
+	StatusCode int    `json:"statusCode" binding:"omitempty,oneof=301 302 307 308"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// SystemSetting is a runtime setting as shown by the settings API. Source
+// tells where Value comes from: "env", "database" or "default". Settings
+// from the environment cannot be changed through the API.
+type SystemSetting struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	Default     interface{} `json:"default"`
+	Source      string      `json:"source"`
+	Env         string      `json:"env"`
+	Minimum     interface{} `json:"minimum,omitempty"`
+	Maximum     interface{} `json:"maximum,omitempty"`
+	MaxLength   int         `json:"maxLength,omitempty"`
+	Options     []string    `json:"options,omitempty"`
+	Description string      `json:"description"`
+}
+
+// MaintenanceStatus tells whether the API is in read-only maintenance mode,
+// with the message for clients and how long they should wait.
+type MaintenanceStatus struct {
+	Enabled    bool          `json:"enabled"`
+	Message    string        `json:"message,omitempty"`
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Sources of setting values
+const (
+	SettingSourceEnv      = "env"
+	SettingSourceDatabase = "database"
+	SettingSourceDefault  = "default"
+)
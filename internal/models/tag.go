@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+type Tag struct {
+	ID        int       `json:"id" db:"id"`
+	WebsiteID int       `json:"websiteId" db:"website_id"`
+	Name      string    `json:"name" db:"name"`
+	PageCount int       `json:"pageCount"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Request/Response DTOs
+type RenameTagRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required,max=64"`
+}
+
+type MergeTagsRequest struct {
+	Sources []string `json:"sources" binding:"required,min=1"`
+	Target  string   `json:"target" binding:"required,max=64"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Types of items in the trash
+const (
+	TrashTypeWebsite = "website"
+	TrashTypePage    = "page"
+	TrashTypeUser    = "user"
+)
+
+// TrashItem is a deleted website, page or user that can still be restored
+// until PurgeAt. Pages trashed together with their website are restored
+// with it and not listed separately.
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	WebsiteID *int      `json:"websiteId,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
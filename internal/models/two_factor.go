@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// UserTOTP is the TOTP enrollment of a user. It is pending until EnabledAt
+// is set. LastStep is the period of the last accepted code, which may not
+// be used again.
+type UserTOTP struct {
+	UserID    int        `json:"userId" db:"user_id"`
+	Secret    string     `json:"-" db:"secret"`
+	EnabledAt *time.Time `json:"enabledAt" db:"enabled_at"`
+	LastStep  int64      `json:"-" db:"last_step"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// LoginChallenge is the second step of a login by a user with two-factor
+// authentication: the password was correct and a code is awaited. Only the
+// SHA-256 hash of the challenge token is stored.
+type LoginChallenge struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"userId" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TwoFactorStatus describes the two-factor authentication of a user.
+// Required is set when one of the user's roles demands it.
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabledAt"`
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining"`
+	Required               bool       `json:"required"`
+}
+
+// TwoFactorSetupResponse carries a new TOTP secret, both as text and as an
+// otpauth:// URI to show as a QR code.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+// TwoFactorCodeRequest carries a code from the authenticator app or, where
+// noted, a recovery code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse carries new recovery codes. They are shown only
+// once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorLoginRequest completes a login that returned a challenge, with
+// a code from the authenticator app or a recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	// UseCookie keeps the session token in an HttpOnly cookie instead of
+	// returning it, for browsers
+	UseCookie bool `json:"useCookie"`
+}
+
+// SetRoleTwoFactorRequest turns the two-factor requirement of a role on or
+// off.
+type SetRoleTwoFactorRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
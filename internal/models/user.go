@@ -5,29 +5,103 @@ import (
 )
 
 type User struct {
-	ID           int       `json:"id" db:"id"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	Name         string    `json:"name" db:"name"`
-	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+	ID                 int       `json:"id" db:"id"`
+	Email              string    `json:"email" db:"email"`
+	PasswordHash       string    `json:"-" db:"password_hash"`
+	Name               string    `json:"name" db:"name"`
+	MustChangePassword bool      `json:"mustChangePassword" db:"must_change_password"`
+	CreatedAt          time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt          time.Time `json:"updatedAt" db:"updated_at"`
+	Version            int       `json:"version" db:"version"`
 }
 
+// UserSession is a login session. Only the SHA-256 hash of the session
+// token is stored. ExpiresAt slides forward with activity but never past
+// AbsoluteExpiresAt.
 type UserSession struct {
+	ID                int       `json:"id" db:"id"`
+	UserID            int       `json:"userId" db:"user_id"`
+	TokenHash         string    `json:"-" db:"token_hash"`
+	UserAgent         string    `json:"userAgent" db:"user_agent"`
+	IPAddress         string    `json:"ipAddress" db:"ip_address"`
+	LastSeenAt        time.Time `json:"lastSeenAt" db:"last_seen_at"`
+	ExpiresAt         time.Time `json:"expiresAt" db:"expires_at"`
+	AbsoluteExpiresAt time.Time `json:"absoluteExpiresAt" db:"absolute_expires_at"`
+	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
+	// Current marks the session making the request in session lists
+	Current bool `json:"current"`
+}
+
+// PasswordResetToken is a single-use password reset token. Only the SHA-256
+// hash of the token is stored.
+type PasswordResetToken struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"userId" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expiresAt" db:"expires_at"`
+	UsedAt    *time.Time `json:"usedAt" db:"used_at"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// Activity types recorded in the user log
+const (
+	ActivityPasswordChanged        = "password_changed"
+	ActivityPasswordResetRequested = "password_reset_requested"
+	ActivityPasswordReset          = "password_reset"
+	ActivityPasswordResetForced    = "password_reset_forced"
+	ActivityLoginFailed            = "login_failed"
+	ActivityAccountLocked          = "account_locked"
+	ActivityAccountUnlocked        = "account_unlocked"
+	ActivitySessionRevoked         = "session_revoked"
+	ActivitySessionsRevoked        = "sessions_revoked"
+	ActivityAPITokenCreated        = "api_token_created"
+	ActivityAPITokenRevoked        = "api_token_revoked"
+	ActivityUserProvisioned        = "user_provisioned"
+	ActivityIdentityLinked         = "identity_linked"
+	ActivityRolesSynced            = "roles_synced"
+	ActivityTwoFactorEnabled       = "two_factor_enabled"
+	ActivityTwoFactorDisabled      = "two_factor_disabled"
+	ActivityTwoFactorReset         = "two_factor_reset"
+	ActivityRecoveryCodesRenewed   = "recovery_codes_renewed"
+	ActivityRecoveryCodeUsed       = "recovery_code_used"
+	ActivityRoleTwoFactorChanged   = "role_two_factor_changed"
+	ActivityInvitationSent         = "invitation_sent"
+	ActivityInvitationResent       = "invitation_resent"
+	ActivityInvitationRevoked      = "invitation_revoked"
+	ActivityInvitationAccepted     = "invitation_accepted"
+	ActivitySystemSettingsChanged  = "system_settings_changed"
+)
+
+// LoginThrottle counts the recent failed logins of an account or a client
+// IP, keyed "account:<email>" or "ip:<address>".
+type LoginThrottle struct {
+	Key           string     `json:"key" db:"key"`
+	Failures      int        `json:"failures" db:"failures"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt" db:"next_attempt_at"`
+	LockedUntil   *time.Time `json:"lockedUntil" db:"locked_until"`
+	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// UserLog is an entry of a user's activity log (audit trail). Details holds
+// a JSON object.
+type UserLog struct {
 	ID           int       `json:"id" db:"id"`
 	UserID       int       `json:"userId" db:"user_id"`
-	SessionToken string    `json:"sessionToken" db:"session_token"`
-	ExpiresAt    time.Time `json:"expiresAt" db:"expires_at"`
+	ActivityType string    `json:"activityType" db:"activity_type"`
+	Details      string    `json:"details" db:"details"`
 	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
 }
 
 type Role struct {
-	ID          int       `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	Permissions string    `json:"permissions" db:"permissions"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+	ID          int    `json:"id" db:"id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	Permissions string `json:"permissions" db:"permissions"`
+	// RequireTwoFactor makes users with the role enroll in two-factor
+	// authentication before they can use the API
+	RequireTwoFactor bool      `json:"requireTwoFactor" db:"require_two_factor"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time `json:"updatedAt" db:"updated_at"`
 }
 
 type UserRole struct {
@@ -47,12 +121,45 @@ type UpdateUserRequest struct {
 	Name  string `json:"name" binding:"omitempty"`
 }
 
+// UserProfile holds the user fields a JSON Merge Patch may change; they are
+// validated with the same rules as CreateUserRequest.
+type UserProfile struct {
+	Email string `json:"email" binding:"required,email"`
+	Name  string `json:"name" binding:"required"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// UseCookie keeps the session token in an HttpOnly cookie instead of
+	// returning it, for browsers
+	UseCookie bool `json:"useCookie"`
 }
 
+// LoginResponse carries a new session: its token, or for cookie sessions
+// the CSRF token that mutating requests must send in X-CSRF-Token. For
+// users with two-factor authentication it carries a challenge instead,
+// which POST /auth/login/2fa completes with a code; ExpiresAt is then the
+// expiry of the challenge.
 type LoginResponse struct {
-	User         User   `json:"user"`
-	SessionToken string `json:"sessionToken"`
+	User              *User     `json:"user,omitempty"`
+	SessionToken      string    `json:"sessionToken,omitempty"`
+	CSRFToken         string    `json:"csrfToken,omitempty"`
+	TwoFactorRequired bool      `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string    `json:"challengeToken,omitempty"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=6"`
 }
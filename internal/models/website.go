@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -15,24 +16,26 @@ type Website struct {
 	GitRepoName    string    `json:"gitRepoName" db:"git_repo_name"`
 	GitRepoBranch  string    `json:"gitRepoBranch" db:"git_repo_branch"`
 	GitAPIToken    string    `json:"-" db:"git_api_token"`
-	Config         string    `json:"config" db:"config"`
+	Config         WebsiteConfig `json:"config" db:"config"`
 	LanguageCode   string    `json:"languageCode" db:"language_code"`
 	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+	Version        int       `json:"version" db:"version"`
 }
 
 // Request/Response DTOs
 type CreateWebsiteRequest struct {
 	Name          string `json:"name" binding:"required"`
 	Slug          string `json:"slug" binding:"required"`
-	Description   string `json:"description" binding:"required"`
-	Slogan        string `json:"slogan" binding:"required"`
+	Description   string `json:"description"`
+	Slogan        string `json:"slogan"`
 	Domain        string `json:"domain" binding:"required"`
 	GitRepoOwner  string `json:"gitRepoOwner" binding:"required"`
 	GitRepoName   string `json:"gitRepoName" binding:"required"`
 	GitRepoBranch string `json:"gitRepoBranch" binding:"required"`
 	GitAPIToken   string `json:"gitApiToken" binding:"required"`
-	Config        string `json:"config" binding:"required"`
+	// Config is validated against the website config schema; defaults apply when omitted
+	Config        json.RawMessage `json:"config" swaggertype:"object"`
 	LanguageCode  string `json:"languageCode" binding:"required,len=2"`
 }
 
@@ -46,6 +49,7 @@ type UpdateWebsiteRequest struct {
 	GitRepoName   string `json:"gitRepoName" binding:"omitempty"`
 	GitRepoBranch string `json:"gitRepoBranch" binding:"omitempty"`
 	GitAPIToken   string `json:"gitApiToken" binding:"omitempty"`
-	Config        string `json:"config" binding:"omitempty"`
+	// Config replaces the whole website config; use PATCH /websites/:id/config for partial updates
+	Config        json.RawMessage `json:"config" swaggertype:"object"`
 	LanguageCode  string `json:"languageCode" binding:"omitempty,len=2"`
 }
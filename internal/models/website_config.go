@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// WebsiteConfigVersion is the current version of the website config schema.
+const WebsiteConfigVersion = 1
+
+// WebsiteConfig is the typed configuration stored in websites.config. The
+// validate tags are enforced on every write and drive the JSON Schema served
+// by GET /websites/config-schema.
+type WebsiteConfig struct {
+	Version    int               `json:"version" validate:"oneof=1" description:"Config schema version"`
+	Theme      *ThemeConfig      `json:"theme,omitempty" description:"Look and feel of the generated site"`
+	Navigation []NavItem         `json:"navigation,omitempty" validate:"omitempty,max=100,dive" description:"Top level navigation entries"`
+	Locales    *LocalesConfig    `json:"locales,omitempty" description:"Languages the site is published in"`
+	Publishing *PublishingConfig `json:"publishing,omitempty" description:"Where the static site build reads and writes content"`
+	Workflow   *WorkflowConfig   `json:"workflow,omitempty" description:"Editorial workflow settings"`
+	Lint       *LintConfig       `json:"lint,omitempty" description:"Markdown lint settings"`
+}
+
+type ThemeConfig struct {
+	Name         string `json:"name,omitempty" validate:"omitempty,max=64" description:"Theme name"`
+	PrimaryColor string `json:"primaryColor,omitempty" validate:"omitempty,hexcolor" description:"Primary color as a hex value, e.g. #0055ff"`
+	LogoURL      string `json:"logoUrl,omitempty" validate:"omitempty,url" description:"URL of the site logo"`
+	FaviconURL   string `json:"faviconUrl,omitempty" validate:"omitempty,url" description:"URL of the favicon"`
+	DarkMode     string `json:"darkMode,omitempty" validate:"omitempty,oneof=auto light dark" description:"Color scheme: auto, light or dark"`
+}
+
+// NavItem links either to a page of the website (Slug) or to a URL.
+type NavItem struct {
+	Title    string    `json:"title" validate:"required,max=100" description:"Label shown in the navigation"`
+	Slug     string    `json:"slug,omitempty" validate:"required_without=URL,excluded_with=URL" description:"Slug of the page to link to"`
+	URL      string    `json:"url,omitempty" validate:"omitempty,url" description:"External URL to link to"`
+	Children []NavItem `json:"children,omitempty" validate:"omitempty,max=100,dive" description:"Nested navigation entries"`
+}
+
+type LocalesConfig struct {
+	Default   string   `json:"default" validate:"required,len=2" description:"Default two-letter language code"`
+	Available []string `json:"available,omitempty" validate:"omitempty,dive,len=2" description:"Two-letter language codes the site is translated into"`
+}
+
+type PublishingConfig struct {
+	BasePath      string `json:"basePath,omitempty" validate:"omitempty,startswith=/" description:"URL path the site is served under, e.g. /docs"`
+	ContentDir    string `json:"contentDir,omitempty" validate:"omitempty,max=255" description:"Repository directory the pages are written to"`
+	AssetsDir     string `json:"assetsDir,omitempty" validate:"omitempty,max=255" description:"Repository directory the assets are written to"`
+	TrailingSlash bool   `json:"trailingSlash,omitempty" description:"Generate URLs with a trailing slash"`
+}
+
+type WorkflowConfig struct {
+	DefaultStatus   string `json:"defaultStatus,omitempty" validate:"omitempty,oneof=draft translating translated ignored published" description:"Status given to new pages"`
+	RequireReview   bool   `json:"requireReview,omitempty" description:"Pages must be reviewed before they are published"`
+	FreezeOnPublish bool   `json:"freezeOnPublish,omitempty" description:"Freeze pages when they are published"`
+}
+
+// Value stores the config as JSON text.
+func (c WebsiteConfig) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads the config leniently: values written before the schema existed
+// may not match it, so mistyped fields are left empty instead of failing the
+// whole row.
+func (c *WebsiteConfig) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case nil:
+		*c = WebsiteConfig{Version: WebsiteConfigVersion}
+		return nil
+	default:
+		return fmt.Errorf("unsupported config type %T", src)
+	}
+
+	*c = WebsiteConfig{}
+	if err := json.Unmarshal(data, c); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+	}
+	if c.Version == 0 {
+		c.Version = WebsiteConfigVersion
+	}
+	return nil
+}
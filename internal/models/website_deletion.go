@@ -0,0 +1,47 @@
+package models
+
+// DeleteWebsiteRequest holds the query parameters of a website deletion.
+type DeleteWebsiteRequest struct {
+	// DryRun reports what would be deleted without deleting anything
+	DryRun bool `form:"dryRun"`
+	// Permanent deletes the website for good instead of moving it to the trash
+	Permanent bool `form:"permanent"`
+	// ConfirmationToken is the token of a dry run; required unless DryRun is set
+	ConfirmationToken string `form:"confirmationToken"`
+}
+
+// WebsiteContent counts the content of a website besides its live pages.
+type WebsiteContent struct {
+	// TrashedPages counts pages that were already in the trash
+	TrashedPages int `json:"trashedPages"`
+	Assets       int `json:"assets"`
+	Links        int `json:"links"`
+	Redirects    int `json:"redirects"`
+	Tags         int `json:"tags"`
+}
+
+// WebsiteDeletion reports what deleting a website removes. Pages in the
+// translating and translated statuses are the website's translations.
+type WebsiteDeletion struct {
+	WebsiteID int    `json:"websiteId"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	DryRun    bool   `json:"dryRun"`
+	Permanent bool   `json:"permanent"`
+	// Pages counts the pages outside the trash, PagesByStatus by status;
+	// they are removed, or moved to the trash with the website
+	Pages         int            `json:"pages"`
+	PagesByStatus map[string]int `json:"pagesByStatus"`
+	// WebsiteContent counts what is removed: everything for a permanent
+	// deletion, nothing for a deletion to the trash
+	WebsiteContent
+	// Kept counts what a deletion to the trash leaves in place for a restore
+	Kept *WebsiteContent `json:"kept,omitempty"`
+	// ConfirmationToken confirms the deletion; it changes whenever the
+	// website or its pages do
+	ConfirmationToken string `json:"confirmationToken"`
+
+	// PageVersions sums the versions of the pages, so that page edits
+	// change the confirmation token
+	PageVersions int `json:"-"`
+}
@@ -0,0 +1,198 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for RS384, ES512 etc.
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// publicKey is a verification key from the issuer's JSON Web Key Set.
+type publicKey struct {
+	rsa *rsa.PublicKey
+	ec  *ecdsa.PublicKey
+}
+
+// jsonWebKey is a key of a JWKS document (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key returns the issuer key with a key ID. An unknown ID refetches the
+// key set, at most once per keysMinRefresh, to pick up key rotation.
+func (p *Provider) key(ctx context.Context, kid, alg string) (publicKey, error) {
+	if len(alg) != 5 || alg[:2] == "HS" {
+		// Only asymmetric signatures prove the issuer signed the token
+		return publicKey{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+	}
+
+	p.mu.Lock()
+	key, ok := p.lookupKey(kid)
+	stale := time.Since(p.keysFetchedAt) >= keysMinRefresh
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return publicKey{}, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return publicKey{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys, p.keysFetchedAt = keys, time.Now()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return publicKey{}, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+}
+
+// lookupKey finds a cached key; tokens without a key ID match the only key
+// of a single-key set. The caller holds p.mu.
+func (p *Provider) lookupKey(kid string) (publicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]publicKey, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]publicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip keys of unsupported types rather than failing the set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (publicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if !e.IsInt64() {
+			return publicKey{}, fmt.Errorf("RSA exponent too large")
+		}
+		return publicKey{rsa: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return publicKey{}, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return publicKey{}, fmt.Errorf("EC point not on curve")
+		}
+		return publicKey{ec: &ecdsa.PublicKey{Curve: curve, X: x, Y: y}}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// verify checks a JWS signature made with alg (RFC 7518).
+func (k publicKey) verify(alg string, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch {
+	case (alg[:2] == "RS" || alg[:2] == "PS") && k.rsa != nil:
+		if alg[:2] == "PS" {
+			return rsa.VerifyPSS(k.rsa, hash, digest, signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(k.rsa, hash, digest, signature)
+	case alg[:2] == "ES" && k.ec != nil:
+		// ES signatures are the fixed-size concatenation of r and s
+		size := (k.ec.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("malformed signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k.ec, digest, r, s) {
+			return fmt.Errorf("signature mismatch")
+		}
+		return nil
+	default:
+		return fmt.Errorf("algorithm %q does not match the key", alg)
+	}
+}
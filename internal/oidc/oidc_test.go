@@ -0,0 +1,284 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID = "dash"
+	testNonce    = "nonce-123"
+)
+
+// testIssuer serves a discovery document and a key set with one RSA and
+// one EC key generated for the test.
+type testIssuer struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	// discoveryIssuer overrides the issuer in the discovery document
+	discoveryIssuer string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		iss := issuer.server.URL
+		if issuer.discoveryIssuer != "" {
+			iss = issuer.discoveryIssuer
+		}
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                iss,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JWKSURI:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []jsonWebKey{
+			{Kty: "RSA", Kid: "rsa-1", Use: "sig", N: b64(rsaKey.N.Bytes()), E: b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{Kty: "EC", Kid: "ec-1", Crv: "P-256", X: b64(ecKey.X.FillBytes(make([]byte, size))),
+				Y: b64(ecKey.Y.FillBytes(make([]byte, size)))},
+		}})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) provider() *Provider {
+	return NewProvider(Config{Issuer: i.server.URL, ClientID: testClientID})
+}
+
+// claims returns valid ID token claims for the test client.
+func (i *testIssuer) claims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":   i.server.URL,
+		"sub":   "user-1",
+		"aud":   testClientID,
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"nonce": testNonce,
+		"email": "user@example.com",
+	}
+}
+
+// sign builds a JWT with the given header and claims, signed according to
+// alg with the issuer's keys.
+func (i *testIssuer) sign(t *testing.T, header, claims map[string]interface{}) string {
+	t.Helper()
+	signed := segment(t, header) + "." + segment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	switch header["alg"] {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:])
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	case "HS256":
+		// Keyed with the public modulus, as in algorithm confusion attacks
+		mac := hmac.New(sha256.New, i.rsaKey.N.Bytes())
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(signature)
+}
+
+func segment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b64(data)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	rs256 := map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}
+
+	tests := []struct {
+		name   string
+		header map[string]interface{}
+		claims func(map[string]interface{})
+		token  func(string) string
+		nonce  string
+		valid  bool
+	}{
+		{name: "valid RS256", header: rs256, valid: true},
+		{name: "valid ES256", header: map[string]interface{}{"alg": "ES256", "kid": "ec-1"}, valid: true},
+		{name: "audience array", header: rs256, valid: true,
+			claims: func(c map[string]interface{}) { c["aud"] = []string{"other", testClientID} }},
+		{name: "matching azp", header: rs256, valid: true,
+			claims: func(c map[string]interface{}) { c["azp"] = testClientID }},
+		{name: "iat within clock skew", header: rs256, valid: true,
+			claims: func(c map[string]interface{}) { c["iat"] = time.Now().Add(30 * time.Second).Unix() }},
+
+		{name: "alg none", header: map[string]interface{}{"alg": "none", "kid": "rsa-1"},
+			token: func(raw string) string { return raw[:strings.LastIndex(raw, ".")+1] }},
+		{name: "HS256", header: map[string]interface{}{"alg": "HS256", "kid": "rsa-1"}},
+		{name: "RS256 with EC key", header: map[string]interface{}{"alg": "RS256", "kid": "ec-1"}},
+		{name: "unknown kid", header: map[string]interface{}{"alg": "RS256", "kid": "rsa-2"}},
+		{name: "tampered signature", header: rs256, token: func(raw string) string {
+			parts := strings.Split(raw, ".")
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			signature[0] ^= 0xff
+			return parts[0] + "." + parts[1] + "." + b64(signature)
+		}},
+		{name: "tampered claims", header: rs256, token: func(raw string) string {
+			parts := strings.Split(raw, ".")
+			claims := map[string]interface{}{}
+			decodeSegment(parts[1], &claims)
+			claims["sub"] = "admin"
+			data, _ := json.Marshal(claims)
+			return parts[0] + "." + b64(data) + "." + parts[2]
+		}},
+		{name: "malformed", header: rs256, token: func(string) string { return "not.a-jwt" }},
+
+		{name: "wrong iss", header: rs256,
+			claims: func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }},
+		{name: "no sub", header: rs256,
+			claims: func(c map[string]interface{}) { delete(c, "sub") }},
+		{name: "wrong aud", header: rs256,
+			claims: func(c map[string]interface{}) { c["aud"] = "other" }},
+		{name: "aud array without client", header: rs256,
+			claims: func(c map[string]interface{}) { c["aud"] = []string{"other"} }},
+		{name: "missing aud", header: rs256,
+			claims: func(c map[string]interface{}) { delete(c, "aud") }},
+		{name: "wrong azp", header: rs256,
+			claims: func(c map[string]interface{}) { c["azp"] = "other" }},
+		{name: "expired", header: rs256,
+			claims: func(c map[string]interface{}) { c["exp"] = time.Now().Add(-2 * clockSkew).Unix() }},
+		{name: "missing exp", header: rs256,
+			claims: func(c map[string]interface{}) { delete(c, "exp") }},
+		{name: "iat in the future", header: rs256,
+			claims: func(c map[string]interface{}) { c["iat"] = time.Now().Add(2 * clockSkew).Unix() }},
+		{name: "wrong nonce", header: rs256, nonce: "other-nonce"},
+		{name: "missing nonce", header: rs256,
+			claims: func(c map[string]interface{}) { delete(c, "nonce") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims()
+			if tt.claims != nil {
+				tt.claims(claims)
+			}
+			raw := issuer.sign(t, tt.header, claims)
+			if tt.token != nil {
+				raw = tt.token(raw)
+			}
+			nonce := testNonce
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			got, err := issuer.provider().VerifyIDToken(context.Background(), raw, nonce)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("VerifyIDToken() error = %v, want valid", err)
+				}
+				if got.Subject != "user-1" || got.Email != "user@example.com" {
+					t.Errorf("claims = %+v", got)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("VerifyIDToken() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.discoveryIssuer = "https://evil.example.com"
+
+	if _, err := issuer.provider().Discover(context.Background()); err == nil {
+		t.Fatal("Discover() accepted a document for another issuer")
+	}
+
+	// Tokens cannot be verified against keys from such a document either
+	token := issuer.sign(t, map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}, issuer.claims())
+	if _, err := issuer.provider().VerifyIDToken(context.Background(), token, testNonce); err == nil {
+		t.Fatal("VerifyIDToken() accepted a token with keys from a mismatched discovery document")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	discovery, err := issuer.provider().Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if discovery.JWKSURI != issuer.server.URL+"/jwks" {
+		t.Errorf("JWKSURI = %q", discovery.JWKSURI)
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636, Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := codeChallenge(verifier); got != want {
+		t.Errorf("codeChallenge() = %q, want %q", got, want)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	issuer := newTestIssuer(t)
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	authURL, err := issuer.provider().AuthCodeURL(context.Background(), "state-1", testNonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	for _, param := range []string{"code_challenge=E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		"code_challenge_method=S256", "state=state-1", "nonce=" + testNonce, "client_id=" + testClientID} {
+		if !strings.Contains(authURL, param) {
+			t.Errorf("AuthCodeURL() = %q, missing %q", authURL, param)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package preferences parses, validates and stores the typed preferences
+// of a user (models.UserPreferences) as key/value rows.
+package preferences
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+// MaxSize caps the preferences of a user, as JSON, in bytes.
+const MaxSize = 16 << 10
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(utils.JSONFieldName)
+	return v
+}
+
+// Parse decodes and validates raw preferences JSON. Unknown keys, mistyped
+// values, rule violations and oversized input are reported as field errors
+// with JSON paths such as "preferences.editor.fontSize". Empty input yields
+// no preferences.
+func Parse(raw []byte) (*models.UserPreferences, []models.FieldError) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return &models.UserPreferences{}, nil
+	}
+	if len(raw) > MaxSize {
+		return nil, []models.FieldError{{Field: "preferences", Code: "max", Message: fmt.Sprintf("must be at most %d bytes", MaxSize)}}
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, []models.FieldError{{Field: "preferences", Code: "invalid_json", Message: "must be valid JSON"}}
+	}
+	if _, ok := generic.(map[string]interface{}); !ok {
+		return nil, []models.FieldError{{Field: "preferences", Code: "type", Message: "must be a JSON object"}}
+	}
+
+	var fieldErrors []models.FieldError
+	for _, path := range utils.UnknownFields(generic, reflect.TypeOf(models.UserPreferences{}), "preferences") {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: path, Code: "unknown_field", Message: "unknown field"})
+	}
+
+	prefs := &models.UserPreferences{}
+	if err := json.Unmarshal(raw, prefs); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return nil, []models.FieldError{{Field: "preferences", Code: "invalid", Message: err.Error()}}
+		}
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   utils.JSONPath("preferences." + typeErr.Field),
+			Code:    "type",
+			Message: "must be " + utils.JSONTypeName(typeErr.Type),
+		})
+		return nil, fieldErrors
+	}
+
+	fieldErrors = append(fieldErrors, Validate(prefs)...)
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+	return prefs, nil
+}
+
+// Validate checks decoded preferences against their rules.
+func Validate(prefs *models.UserPreferences) []models.FieldError {
+	err := validate.Struct(prefs)
+	if err == nil {
+		return nil
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []models.FieldError{{Field: "preferences", Code: "invalid", Message: err.Error()}}
+	}
+
+	var fieldErrors []models.FieldError
+	for _, e := range validationErrors {
+		field := "preferences" + strings.TrimPrefix(e.Namespace(), "UserPreferences")
+		fieldErrors = append(fieldErrors, models.FieldError{Field: field, Code: e.Tag(), Message: utils.ValidationMessage(e)})
+	}
+	return fieldErrors
+}
+
+// Encode splits preferences into user_config rows: each top-level key that
+// is set maps to its value as JSON.
+func Encode(prefs *models.UserPreferences) (map[string]string, error) {
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to encode preferences: %w", err)
+	}
+
+	values := make(map[string]string, len(keys))
+	for key, value := range keys {
+		values[key] = string(value)
+	}
+	return values, nil
+}
+
+// Decode assembles preferences from user_config rows. It is lenient:
+// rows written by older versions may hold values that no longer match,
+// which are left out instead of failing; unknown keys are ignored.
+func Decode(values map[string]string) *models.UserPreferences {
+	keys := make(map[string]json.RawMessage, len(values))
+	for key, value := range values {
+		row, err := json.Marshal(map[string]json.RawMessage{key: json.RawMessage(value)})
+		if err != nil {
+			continue
+		}
+		if json.Unmarshal(row, &models.UserPreferences{}) == nil {
+			keys[key] = json.RawMessage(value)
+		}
+	}
+
+	prefs := &models.UserPreferences{}
+	if data, err := json.Marshal(keys); err == nil {
+		json.Unmarshal(data, prefs)
+	}
+	return prefs
+}
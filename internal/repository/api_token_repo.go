@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type APITokenRepository struct {
+	db DBTX
+}
+
+func NewAPITokenRepository(db DBTX) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *APITokenRepository) WithTx(tx *sql.Tx) *APITokenRepository {
+	return &APITokenRepository{db: tx}
+}
+
+const apiTokenColumns = `id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, last_used_ip, created_at`
+
+// scanAPIToken reads a token row. Scopes are stored space-separated, as in
+// OAuth scope strings.
+func scanAPIToken(row interface{ Scan(...interface{}) error }) (*models.APIToken, error) {
+	token := &models.APIToken{}
+	var scopes string
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, &token.TokenHash, &scopes,
+		&token.ExpiresAt, &token.LastUsedAt, &token.LastUsedIP, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	token.Scopes = strings.Fields(scopes)
+	return token, nil
+}
+
+func (r *APITokenRepository) Create(token *models.APIToken) error {
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, token.UserID, token.Name, token.TokenPrefix, token.TokenHash,
+		strings.Join(token.Scopes, " "), token.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get API token ID: %w", err)
+	}
+
+	token.ID = int(id)
+	token.CreatedAt = now
+	return nil
+}
+
+// GetByHash returns the unexpired token with a token hash.
+func (r *APITokenRepository) GetByHash(tokenHash string) (*models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens WHERE token_hash = ? AND (expires_at IS NULL OR expires_at > ?)
+	`
+	token, err := scanAPIToken(r.db.QueryRow(query, tokenHash, time.Now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("API token %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	return token, nil
+}
+
+// GetByUser returns the tokens of a user, expired ones included, newest
+// first.
+func (r *APITokenRepository) GetByUser(userID int) ([]*models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*models.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// Touch records the use of a token from a client IP.
+func (r *APITokenRepository) Touch(id int, ip string, lastUsedAt time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = ?, last_used_ip = ? WHERE id = ?`
+	_, err := r.db.Exec(query, lastUsedAt, ip, id)
+	if err != nil {
+		return fmt.Errorf("failed to update API token: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUser removes all tokens of a user and returns how many there were.
+func (r *APITokenRepository) DeleteByUser(userID int) (int, error) {
+	query := `DELETE FROM api_tokens WHERE user_id = ?`
+	result, err := r.db.Exec(query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete API tokens: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// DeleteUserToken removes one of a user's tokens.
+func (r *APITokenRepository) DeleteUserToken(userID, id int) error {
+	query := `DELETE FROM api_tokens WHERE id = ? AND user_id = ?`
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete API token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("API token %w", ErrNotFound)
+	}
+	return nil
+}
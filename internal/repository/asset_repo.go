@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type AssetRepository struct {
+	db DBTX
+}
+
+func NewAssetRepository(db DBTX) *AssetRepository {
+	return &AssetRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *AssetRepository) WithTx(tx *sql.Tx) *AssetRepository {
+	return &AssetRepository{db: tx}
+}
+
+func (r *AssetRepository) Create(asset *models.PageAsset) error {
+	query := `
+		INSERT INTO page_assets (page_id, bucket_key, mime_type, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, asset.PageID, asset.BucketKey, asset.MimeType, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create page asset: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get page asset ID: %w", err)
+	}
+
+	asset.ID = int(id)
+	asset.CreatedAt = now
+	asset.UpdatedAt = now
+	return nil
+}
+
+func (r *AssetRepository) GetByPageID(pageID int) ([]*models.PageAsset, error) {
+	query := `
+		SELECT id, page_id, bucket_key, mime_type, created_at, updated_at
+		FROM page_assets WHERE page_id = ? ORDER BY id
+	`
+	return r.query(query, pageID)
+}
+
+// GetByWebsiteID lists the assets of every page in a website that is not
+// in the trash.
+func (r *AssetRepository) GetByWebsiteID(websiteID int) ([]*models.PageAsset, error) {
+	query := `
+		SELECT a.id, a.page_id, a.bucket_key, a.mime_type, a.created_at, a.updated_at
+		FROM page_assets a
+		JOIN pages p ON p.id = a.page_id
+		WHERE p.website_id = ? AND p.deleted_at IS NULL ORDER BY a.page_id, a.id
+	`
+	return r.query(query, websiteID)
+}
+
+func (r *AssetRepository) DeleteByPageID(pageID int) error {
+	query := `DELETE FROM page_assets WHERE page_id = ?`
+	_, err := r.db.Exec(query, pageID)
+	if err != nil {
+		return fmt.Errorf("failed to delete page assets: %w", err)
+	}
+	return nil
+}
+
+func (r *AssetRepository) query(query string, args ...interface{}) ([]*models.PageAsset, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []*models.PageAsset{}
+	for rows.Next() {
+		asset := &models.PageAsset{}
+		err := rows.Scan(&asset.ID, &asset.PageID, &asset.BucketKey, &asset.MimeType,
+			&asset.CreatedAt, &asset.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan page asset: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is wrapped by the errors repositories return when no row
+// matches, e.g. "page not found".
+var ErrNotFound = errors.New("not found")
+
+// ErrVersionConflict is wrapped by the errors of versioned updates when the
+// row was changed by someone else since it was read.
+var ErrVersionConflict = errors.New("was modified concurrently")
+
+// DBTX is implemented by both *sql.DB and *sql.Tx, so repositories can run
+// the same queries inside or outside a transaction.
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// checkVersionedUpdate interprets the result of an UPDATE guarded by
+// "WHERE id = ? AND version = ?": when no row matched, it tells a missing
+// row from one whose version moved on. Rows in the trash count as missing.
+func checkVersionedUpdate(db DBTX, result sql.Result, table, resource string, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE id = ? AND deleted_at IS NULL`, id).Scan(&count); err != nil {
+		return fmt.Errorf("failed to get %s: %w", resource, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s %w", resource, ErrNotFound)
+	}
+	return fmt.Errorf("%s %w", resource, ErrVersionConflict)
+}
+
+// updateOne runs an UPDATE that must match one row of resource and
+// reports ErrNotFound when it matches none.
+func updateOne(db DBTX, resource, query string, args ...interface{}) error {
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", resource, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s %w", resource, ErrNotFound)
+	}
+	return nil
+}
+
+// WithTx runs fn inside a transaction, committing if fn returns nil and
+// rolling back otherwise.
+func (m *TxManager) WithTx(fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type InvitationRepository struct {
+	db DBTX
+}
+
+func NewInvitationRepository(db DBTX) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *InvitationRepository) WithTx(tx *sql.Tx) *InvitationRepository {
+	return &InvitationRepository{db: tx}
+}
+
+// invitationSelect reads invitations together with the name of their role.
+const invitationSelect = `
+	SELECT invitations.id, invitations.email, invitations.role_id, roles.name, invitations.invited_by,
+		invitations.token_hash, invitations.expires_at, invitations.sent_at, invitations.created_at
+	FROM invitations JOIN roles ON roles.id = invitations.role_id
+`
+
+func scanInvitation(row interface{ Scan(...interface{}) error }) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	err := row.Scan(
+		&invitation.ID, &invitation.Email, &invitation.RoleID, &invitation.Role, &invitation.InvitedBy,
+		&invitation.TokenHash, &invitation.ExpiresAt, &invitation.SentAt, &invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *InvitationRepository) Create(invitation *models.Invitation) error {
+	query := `
+		INSERT INTO invitations (email, role_id, invited_by, token_hash, expires_at, sent_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, invitation.Email, invitation.RoleID, invitation.InvitedBy,
+		invitation.TokenHash, invitation.ExpiresAt, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get invitation ID: %w", err)
+	}
+
+	invitation.ID = int(id)
+	invitation.SentAt = now
+	invitation.CreatedAt = now
+	return nil
+}
+
+func (r *InvitationRepository) GetByID(id int) (*models.Invitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRow(invitationSelect+` WHERE invitations.id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invitation %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// GetByEmail returns the invitation of an email address, expired or not.
+func (r *InvitationRepository) GetByEmail(email string) (*models.Invitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRow(invitationSelect+` WHERE invitations.email = ?`, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invitation %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// GetByTokenHash returns the unexpired invitation with a token hash.
+func (r *InvitationRepository) GetByTokenHash(tokenHash string) (*models.Invitation, error) {
+	query := invitationSelect + ` WHERE invitations.token_hash = ? AND invitations.expires_at > ?`
+	invitation, err := scanInvitation(r.db.QueryRow(query, tokenHash, time.Now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invitation %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// GetAll returns the pending invitations, expired ones included, newest
+// first.
+func (r *InvitationRepository) GetAll() ([]*models.Invitation, error) {
+	rows, err := r.db.Query(invitationSelect + ` ORDER BY invitations.created_at DESC, invitations.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []*models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// Renew replaces the token of an invitation and extends its expiry, which
+// invalidates the previously mailed link.
+func (r *InvitationRepository) Renew(invitation *models.Invitation, tokenHash string, expiresAt time.Time) error {
+	query := `UPDATE invitations SET token_hash = ?, expires_at = ?, sent_at = ? WHERE id = ?`
+	now := time.Now()
+	result, err := r.db.Exec(query, tokenHash, expiresAt, now, invitation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invitation %w", ErrNotFound)
+	}
+
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = expiresAt
+	invitation.SentAt = now
+	return nil
+}
+
+// Delete removes an invitation. An invitation that is already gone, e.g.
+// accepted concurrently, is reported as not found, so it is accepted only
+// once.
+func (r *InvitationRepository) Delete(id int) error {
+	query := `DELETE FROM invitations WHERE id = ?`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invitation %w", ErrNotFound)
+	}
+	return nil
+}
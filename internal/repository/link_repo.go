@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type LinkRepository struct {
+	db DBTX
+}
+
+func NewLinkRepository(db DBTX) *LinkRepository {
+	return &LinkRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *LinkRepository) WithTx(tx *sql.Tx) *LinkRepository {
+	return &LinkRepository{db: tx}
+}
+
+// ReplaceForPage replaces all outbound links recorded for a page.
+func (r *LinkRepository) ReplaceForPage(pageID int, links []*models.PageLink) error {
+	if err := r.DeleteBySourcePage(pageID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO page_links (website_id, source_page_id, target_slug, link_text,
+			line_number, column_number, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	for _, link := range links {
+		result, err := r.db.Exec(query, link.WebsiteID, pageID, link.TargetSlug, link.LinkText,
+			link.Line, link.Column, now)
+		if err != nil {
+			return fmt.Errorf("failed to create page link: %w", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get page link ID: %w", err)
+		}
+
+		link.ID = int(id)
+		link.SourcePageID = pageID
+		link.CreatedAt = now
+	}
+	return nil
+}
+
+func (r *LinkRepository) DeleteBySourcePage(pageID int) error {
+	query := `DELETE FROM page_links WHERE source_page_id = ?`
+	_, err := r.db.Exec(query, pageID)
+	if err != nil {
+		return fmt.Errorf("failed to delete page links: %w", err)
+	}
+	return nil
+}
+
+const linkDetailSelect = `
+	SELECT l.id, l.website_id, l.source_page_id, l.target_slug, l.link_text,
+		l.line_number, l.column_number, l.created_at, s.slug, s.title, t.id, t.title
+	FROM page_links l
+	JOIN pages s ON s.id = l.source_page_id
+	LEFT JOIN pages t ON t.slug = l.target_slug AND t.website_id = l.website_id AND t.deleted_at IS NULL
+`
+
+func (r *LinkRepository) GetOutbound(pageID int) ([]*models.PageLinkDetail, error) {
+	query := linkDetailSelect + `WHERE l.source_page_id = ? ORDER BY l.line_number, l.column_number`
+	return r.queryDetails(query, pageID)
+}
+
+func (r *LinkRepository) GetInbound(websiteID int, slug string) ([]*models.PageLinkDetail, error) {
+	query := linkDetailSelect + `WHERE l.website_id = ? AND l.target_slug = ? ORDER BY s.slug, l.line_number`
+	return r.queryDetails(query, websiteID, slug)
+}
+
+func (r *LinkRepository) GetBroken(websiteID int) ([]*models.PageLinkDetail, error) {
+	query := linkDetailSelect + `WHERE l.website_id = ? AND t.id IS NULL ORDER BY s.slug, l.line_number`
+	return r.queryDetails(query, websiteID)
+}
+
+func (r *LinkRepository) CountByWebsite(websiteID int) (int, error) {
+	query := `SELECT COUNT(*) FROM page_links WHERE website_id = ?`
+	var count int
+	if err := r.db.QueryRow(query, websiteID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count page links: %w", err)
+	}
+	return count, nil
+}
+
+func (r *LinkRepository) queryDetails(query string, args ...interface{}) ([]*models.PageLinkDetail, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*models.PageLinkDetail{}
+	for rows.Next() {
+		link := &models.PageLinkDetail{}
+		err := rows.Scan(
+			&link.ID, &link.WebsiteID, &link.SourcePageID, &link.TargetSlug, &link.LinkText,
+			&link.Line, &link.Column, &link.CreatedAt, &link.SourceSlug, &link.SourceTitle,
+			&link.TargetPageID, &link.TargetTitle,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan page link: %w", err)
+		}
+		link.Broken = link.TargetPageID == nil
+		links = append(links, link)
+	}
+	return links, nil
+}
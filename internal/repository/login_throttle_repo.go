@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type LoginThrottleRepository struct {
+	db DBTX
+}
+
+func NewLoginThrottleRepository(db DBTX) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *LoginThrottleRepository) WithTx(tx *sql.Tx) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: tx}
+}
+
+func (r *LoginThrottleRepository) Get(key string) (*models.LoginThrottle, error) {
+	query := `
+		SELECT key, failures, next_attempt_at, locked_until, updated_at
+		FROM login_throttles WHERE key = ?
+	`
+	throttle := &models.LoginThrottle{}
+	err := r.db.QueryRow(query, key).Scan(
+		&throttle.Key, &throttle.Failures, &throttle.NextAttemptAt,
+		&throttle.LockedUntil, &throttle.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("login throttle %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get login throttle: %w", err)
+	}
+	return throttle, nil
+}
+
+// IncrementFailures counts a failed login for key. Counters last updated
+// before resetBefore start over, so old failures expire.
+func (r *LoginThrottleRepository) IncrementFailures(key string, resetBefore time.Time) error {
+	query := `
+		INSERT INTO login_throttles (key, failures, updated_at) VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.updated_at < ? THEN 1 ELSE login_throttles.failures + 1 END,
+			updated_at = excluded.updated_at
+	`
+	_, err := r.db.Exec(query, key, time.Now(), resetBefore)
+	if err != nil {
+		return fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return nil
+}
+
+// SetBlock stores until when key must wait for its next attempt and, when
+// locked, until when it is locked out.
+func (r *LoginThrottleRepository) SetBlock(key string, nextAttemptAt, lockedUntil *time.Time) error {
+	query := `UPDATE login_throttles SET next_attempt_at = ?, locked_until = ? WHERE key = ?`
+	_, err := r.db.Exec(query, nextAttemptAt, lockedUntil, key)
+	if err != nil {
+		return fmt.Errorf("failed to update login throttle: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginThrottleRepository) Delete(key string) error {
+	query := `DELETE FROM login_throttles WHERE key = ?`
+	_, err := r.db.Exec(query, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete login throttle: %w", err)
+	}
+	return nil
+}
+
+// DeleteStale removes counters that were last updated before a time.
+func (r *LoginThrottleRepository) DeleteStale(before time.Time) error {
+	query := `DELETE FROM login_throttles WHERE updated_at < ?`
+	_, err := r.db.Exec(query, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete stale login throttles: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type OIDCRepository struct {
+	db DBTX
+}
+
+func NewOIDCRepository(db DBTX) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *OIDCRepository) WithTx(tx *sql.Tx) *OIDCRepository {
+	return &OIDCRepository{db: tx}
+}
+
+func (r *OIDCRepository) CreateLoginState(state *models.OIDCLoginState) error {
+	query := `
+		INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, state.StateHash, state.Nonce, state.CodeVerifier, state.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create login state: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get login state ID: %w", err)
+	}
+
+	state.ID = int(id)
+	state.CreatedAt = now
+	return nil
+}
+
+// GetLoginStateByHash returns the unexpired login state with a state hash.
+func (r *OIDCRepository) GetLoginStateByHash(stateHash string) (*models.OIDCLoginState, error) {
+	query := `
+		SELECT id, state_hash, nonce, code_verifier, expires_at, created_at
+		FROM oidc_login_states WHERE state_hash = ? AND expires_at > ?
+	`
+	state := &models.OIDCLoginState{}
+	err := r.db.QueryRow(query, stateHash, time.Now()).Scan(
+		&state.ID, &state.StateHash, &state.Nonce, &state.CodeVerifier, &state.ExpiresAt, &state.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("login state %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get login state: %w", err)
+	}
+	return state, nil
+}
+
+// DeleteLoginState consumes a login state. It fails with ErrNotFound if the
+// state is already gone, so each state finishes at most one login.
+func (r *OIDCRepository) DeleteLoginState(id int) error {
+	query := `DELETE FROM oidc_login_states WHERE id = ?`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete login state: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("login state %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (r *OIDCRepository) DeleteExpiredLoginStates() error {
+	query := `DELETE FROM oidc_login_states WHERE expires_at <= ?`
+	_, err := r.db.Exec(query, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired login states: %w", err)
+	}
+	return nil
+}
+
+func (r *OIDCRepository) GetIdentity(issuer, subject string) (*models.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, issuer, subject, last_login_at, created_at
+		FROM user_identities WHERE issuer = ? AND subject = ?
+	`
+	identity := &models.UserIdentity{}
+	err := r.db.QueryRow(query, issuer, subject).Scan(
+		&identity.ID, &identity.UserID, &identity.Issuer, &identity.Subject, &identity.LastLoginAt, &identity.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("identity %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	return identity, nil
+}
+
+func (r *OIDCRepository) CreateIdentity(identity *models.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, issuer, subject, last_login_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, identity.UserID, identity.Issuer, identity.Subject, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get identity ID: %w", err)
+	}
+
+	identity.ID = int(id)
+	identity.LastLoginAt = &now
+	identity.CreatedAt = now
+	return nil
+}
+
+// TouchIdentity records a login through an identity.
+func (r *OIDCRepository) TouchIdentity(id int) error {
+	query := `UPDATE user_identities SET last_login_at = ? WHERE id = ?`
+	_, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update identity: %w", err)
+	}
+	return nil
+}
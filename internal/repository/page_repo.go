@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -9,22 +10,40 @@ import (
 )
 
 type PageRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewPageRepository(db *sql.DB) *PageRepository {
+func NewPageRepository(db DBTX) *PageRepository {
 	return &PageRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *PageRepository) WithTx(tx *sql.Tx) *PageRepository {
+	return &PageRepository{db: tx}
+}
+
+// pageSelect loads pages together with their tags, in page order.
+const pageSelect = `
+	SELECT id, website_id, title, slug, description, markdown_content,
+		(SELECT json_group_array(name) FROM (
+			SELECT t.name FROM page_tags pt JOIN tags t ON t.id = pt.tag_id
+			WHERE pt.page_id = pages.id ORDER BY pt.position
+		)),
+		freeze_status, status, last_status_change_at, scheduled_publish_at, created_at, updated_at, version
+	FROM pages
+`
+
+// Create inserts the page and links its tags, creating tags that do not
+// exist in the website yet.
 func (r *PageRepository) Create(page *models.Page) error {
 	query := `
-		INSERT INTO pages (website_id, title, slug, description, markdown_content, tags, 
+		INSERT INTO pages (website_id, title, slug, description, markdown_content,
 			freeze_status, status, last_status_change_at, scheduled_publish_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
 	result, err := r.db.Exec(query, page.WebsiteID, page.Title, page.Slug, page.Description,
-		page.MarkdownContent, page.Tags, page.FreezeStatus, page.Status, now,
+		page.MarkdownContent, page.FreezeStatus, page.Status, now,
 		page.ScheduledPublishAt, now, now)
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
@@ -39,24 +58,20 @@ func (r *PageRepository) Create(page *models.Page) error {
 	page.LastStatusChangeAt = now
 	page.CreatedAt = now
 	page.UpdatedAt = now
-	return nil
+	page.Version = 1
+
+	if page.Tags == nil {
+		page.Tags = []string{}
+	}
+	return replacePageTags(r.db, page.WebsiteID, page.ID, page.Tags)
 }
 
 func (r *PageRepository) GetByID(id int) (*models.Page, error) {
-	query := `
-		SELECT id, website_id, title, slug, description, markdown_content, tags, 
-			freeze_status, status, last_status_change_at, scheduled_publish_at, created_at, updated_at
-		FROM pages WHERE id = ?
-	`
-	page := &models.Page{}
-	err := r.db.QueryRow(query, id).Scan(
-		&page.ID, &page.WebsiteID, &page.Title, &page.Slug, &page.Description,
-		&page.MarkdownContent, &page.Tags, &page.FreezeStatus, &page.Status,
-		&page.LastStatusChangeAt, &page.ScheduledPublishAt, &page.CreatedAt, &page.UpdatedAt,
-	)
+	query := pageSelect + `WHERE id = ? AND deleted_at IS NULL`
+	page, err := scanPage(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("page not found")
+			return nil, fmt.Errorf("page %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get page: %w", err)
 	}
@@ -64,20 +79,11 @@ func (r *PageRepository) GetByID(id int) (*models.Page, error) {
 }
 
 func (r *PageRepository) GetBySlug(slug string) (*models.Page, error) {
-	query := `
-		SELECT id, website_id, title, slug, description, markdown_content, tags, 
-			freeze_status, status, last_status_change_at, scheduled_publish_at, created_at, updated_at
-		FROM pages WHERE slug = ?
-	`
-	page := &models.Page{}
-	err := r.db.QueryRow(query, slug).Scan(
-		&page.ID, &page.WebsiteID, &page.Title, &page.Slug, &page.Description,
-		&page.MarkdownContent, &page.Tags, &page.FreezeStatus, &page.Status,
-		&page.LastStatusChangeAt, &page.ScheduledPublishAt, &page.CreatedAt, &page.UpdatedAt,
-	)
+	query := pageSelect + `WHERE slug = ? AND deleted_at IS NULL`
+	page, err := scanPage(r.db.QueryRow(query, slug))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("page not found")
+			return nil, fmt.Errorf("page %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get page: %w", err)
 	}
@@ -85,90 +91,153 @@ func (r *PageRepository) GetBySlug(slug string) (*models.Page, error) {
 }
 
 func (r *PageRepository) GetAll() ([]*models.Page, error) {
-	query := `
-		SELECT id, website_id, title, slug, description, markdown_content, tags, 
-			freeze_status, status, last_status_change_at, scheduled_publish_at, created_at, updated_at
-		FROM pages ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(query)
+	query := pageSelect + `WHERE deleted_at IS NULL ORDER BY created_at DESC`
+	pages, err := r.queryPages(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pages: %w", err)
 	}
-	defer rows.Close()
-
-	var pages []*models.Page
-	for rows.Next() {
-		page := &models.Page{}
-		err := rows.Scan(
-			&page.ID, &page.WebsiteID, &page.Title, &page.Slug, &page.Description,
-			&page.MarkdownContent, &page.Tags, &page.FreezeStatus, &page.Status,
-			&page.LastStatusChangeAt, &page.ScheduledPublishAt, &page.CreatedAt, &page.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan page: %w", err)
-		}
-		pages = append(pages, page)
-	}
 	return pages, nil
 }
 
 func (r *PageRepository) GetByWebsiteID(websiteID int) ([]*models.Page, error) {
-	query := `
-		SELECT id, website_id, title, slug, description, markdown_content, tags, 
-			freeze_status, status, last_status_change_at, scheduled_publish_at, created_at, updated_at
-		FROM pages WHERE website_id = ? ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(query, websiteID)
+	query := pageSelect + `WHERE website_id = ? AND deleted_at IS NULL ORDER BY created_at DESC`
+	pages, err := r.queryPages(query, websiteID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pages by website: %w", err)
 	}
-	defer rows.Close()
+	return pages, nil
+}
 
-	var pages []*models.Page
-	for rows.Next() {
-		page := &models.Page{}
-		err := rows.Scan(
-			&page.ID, &page.WebsiteID, &page.Title, &page.Slug, &page.Description,
-			&page.MarkdownContent, &page.Tags, &page.FreezeStatus, &page.Status,
-			&page.LastStatusChangeAt, &page.ScheduledPublishAt, &page.CreatedAt, &page.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan page: %w", err)
-		}
-		pages = append(pages, page)
+// Find returns the pages matching every non-zero field of filter.
+func (r *PageRepository) Find(filter *models.PageFilter) ([]*models.Page, error) {
+	query := pageSelect + `WHERE deleted_at IS NULL`
+	var args []interface{}
+	if filter.WebsiteID != 0 {
+		query += ` AND website_id = ?`
+		args = append(args, filter.WebsiteID)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if filter.FreezeStatus != nil {
+		query += ` AND freeze_status = ?`
+		args = append(args, *filter.FreezeStatus)
+	}
+	if filter.Tag != "" {
+		query += ` AND EXISTS (
+			SELECT 1 FROM page_tags pt JOIN tags t ON t.id = pt.tag_id
+			WHERE pt.page_id = pages.id AND t.name = ?
+		)`
+		args = append(args, filter.Tag)
+	}
+	query += ` ORDER BY id`
+
+	pages, err := r.queryPages(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pages: %w", err)
 	}
 	return pages, nil
 }
 
+// Update saves the page and replaces its tags. The update only applies if
+// the stored version still equals page.Version, which is then incremented.
 func (r *PageRepository) Update(id int, page *models.Page) error {
 	query := `
-		UPDATE pages SET title = ?, slug = ?, description = ?, markdown_content = ?, 
-			tags = ?, freeze_status = ?, status = ?, last_status_change_at = ?, 
-			scheduled_publish_at = ?, updated_at = ?
-		WHERE id = ?
+		UPDATE pages SET title = ?, slug = ?, description = ?, markdown_content = ?,
+			freeze_status = ?, status = ?, last_status_change_at = ?,
+			scheduled_publish_at = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
 	now := time.Now()
 	result, err := r.db.Exec(query, page.Title, page.Slug, page.Description,
-		page.MarkdownContent, page.Tags, page.FreezeStatus, page.Status,
-		page.LastStatusChangeAt, page.ScheduledPublishAt, now, id)
+		page.MarkdownContent, page.FreezeStatus, page.Status,
+		page.LastStatusChangeAt, page.ScheduledPublishAt, now, id, page.Version)
 	if err != nil {
 		return fmt.Errorf("failed to update page: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if err := checkVersionedUpdate(r.db, result, "pages", "page", id); err != nil {
+		return err
+	}
+
+	page.UpdatedAt = now
+	page.Version++
+
+	if page.Tags == nil {
+		page.Tags = []string{}
+	}
+	return replacePageTags(r.db, page.WebsiteID, id, page.Tags)
+}
+
+// GetTrashedByID returns a page in the trash.
+func (r *PageRepository) GetTrashedByID(id int) (*models.Page, error) {
+	query := pageSelect + `WHERE id = ? AND deleted_at IS NOT NULL`
+	page, err := scanPage(r.db.QueryRow(query, id))
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("page %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get page: %w", err)
 	}
+	return page, nil
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("page not found")
+// GetTrashedWithWebsite lists the pages moved to the trash together with
+// their website.
+func (r *PageRepository) GetTrashedWithWebsite(websiteID int) ([]*models.Page, error) {
+	query := pageSelect + `WHERE website_id = ? AND deleted_at = (SELECT deleted_at FROM websites WHERE id = ?) ORDER BY id`
+	pages, err := r.queryPages(query, websiteID, websiteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed pages: %w", err)
 	}
+	return pages, nil
+}
 
-	page.UpdatedAt = now
-	return nil
+// GetIDsByWebsite lists the IDs of every page of a website, including
+// pages in the trash.
+func (r *PageRepository) GetIDsByWebsite(websiteID int) ([]int, error) {
+	rows, err := r.db.Query(`SELECT id FROM pages WHERE website_id = ? ORDER BY id`, websiteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan page ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
 }
 
+// Trash moves a page to the trash; its slug becomes free.
+func (r *PageRepository) Trash(id int, deletedAt time.Time) error {
+	query := `UPDATE pages SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	return updateOne(r.db, "page", query, deletedAt, id)
+}
+
+// Restore takes a page out of the trash.
+func (r *PageRepository) Restore(id int) error {
+	query := `UPDATE pages SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	return updateOne(r.db, "page", query, id)
+}
+
+// Delete removes the page and its tag links for good, whether or not it is
+// in the trash.
 func (r *PageRepository) Delete(id int) error {
+	var websiteID int
+	err := r.db.QueryRow(`SELECT website_id FROM pages WHERE id = ?`, id).Scan(&websiteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("page %w", ErrNotFound)
+		}
+		return fmt.Errorf("failed to get page: %w", err)
+	}
+
 	query := `DELETE FROM pages WHERE id = ?`
 	result, err := r.db.Exec(query, id)
 	if err != nil {
@@ -181,8 +250,44 @@ func (r *PageRepository) Delete(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("page not found")
+		return fmt.Errorf("page %w", ErrNotFound)
+	}
+
+	return replacePageTags(r.db, websiteID, id, nil)
+}
+
+func (r *PageRepository) queryPages(query string, args ...interface{}) ([]*models.Page, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []*models.Page
+	for rows.Next() {
+		page, err := scanPage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan page: %w", err)
+		}
+		pages = append(pages, page)
 	}
+	return pages, nil
+}
 
-	return nil
+func scanPage(row rowScanner) (*models.Page, error) {
+	page := &models.Page{}
+	var tags string
+	err := row.Scan(
+		&page.ID, &page.WebsiteID, &page.Title, &page.Slug, &page.Description,
+		&page.MarkdownContent, &tags, &page.FreezeStatus, &page.Status,
+		&page.LastStatusChangeAt, &page.ScheduledPublishAt, &page.CreatedAt, &page.UpdatedAt,
+		&page.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &page.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode page tags: %w", err)
+	}
+	return page, nil
 }
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type RedirectRepository struct {
+	db DBTX
+}
+
+func NewRedirectRepository(db DBTX) *RedirectRepository {
+	return &RedirectRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *RedirectRepository) WithTx(tx *sql.Tx) *RedirectRepository {
+	return &RedirectRepository{db: tx}
+}
+
+// redirectSelect resolves the current slug of the redirect target.
+const redirectSelect = `
+	SELECT r.id, r.resource_type, r.website_id, r.old_slug, r.target_id,
+		COALESCE(p.slug, w.slug, ''), r.status_code, r.is_manual, r.created_at, r.updated_at
+	FROM slug_redirects r
+	LEFT JOIN pages p ON r.resource_type = 'page' AND p.id = r.target_id
+	LEFT JOIN websites w ON r.resource_type = 'website' AND w.id = r.target_id
+`
+
+func (r *RedirectRepository) Create(redirect *models.Redirect) error {
+	query := `
+		INSERT INTO slug_redirects (resource_type, website_id, old_slug, target_id,
+			status_code, is_manual, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, redirect.ResourceType, redirect.WebsiteID, redirect.OldSlug,
+		redirect.TargetID, redirect.StatusCode, redirect.IsManual, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create redirect: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get redirect ID: %w", err)
+	}
+
+	redirect.ID = int(id)
+	redirect.CreatedAt = now
+	redirect.UpdatedAt = now
+	return nil
+}
+
+func (r *RedirectRepository) GetByID(id int) (*models.Redirect, error) {
+	query := redirectSelect + `WHERE r.id = ?`
+	redirect, err := scanRedirect(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("redirect %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get redirect: %w", err)
+	}
+	return redirect, nil
+}
+
+func (r *RedirectRepository) GetBySlug(resourceType, slug string) (*models.Redirect, error) {
+	query := redirectSelect + `WHERE r.resource_type = ? AND r.old_slug = ?`
+	redirect, err := scanRedirect(r.db.QueryRow(query, resourceType, slug))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("redirect %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get redirect: %w", err)
+	}
+	return redirect, nil
+}
+
+// GetAll lists redirects, optionally filtered by website (websiteID > 0) and
+// resource type (non-empty resourceType).
+func (r *RedirectRepository) GetAll(websiteID int, resourceType string) ([]*models.Redirect, error) {
+	query := redirectSelect + `
+		WHERE (? = 0 OR r.website_id = ?) AND (? = '' OR r.resource_type = ?)
+		ORDER BY r.resource_type, r.old_slug
+	`
+	rows, err := r.db.Query(query, websiteID, websiteID, resourceType, resourceType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redirects: %w", err)
+	}
+	defer rows.Close()
+
+	redirects := []*models.Redirect{}
+	for rows.Next() {
+		redirect, err := scanRedirect(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan redirect: %w", err)
+		}
+		redirects = append(redirects, redirect)
+	}
+	return redirects, nil
+}
+
+func (r *RedirectRepository) Update(id int, redirect *models.Redirect) error {
+	query := `
+		UPDATE slug_redirects SET website_id = ?, old_slug = ?, target_id = ?, status_code = ?, updated_at = ?
+		WHERE id = ?
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, redirect.WebsiteID, redirect.OldSlug, redirect.TargetID,
+		redirect.StatusCode, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to update redirect: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("redirect %w", ErrNotFound)
+	}
+
+	redirect.UpdatedAt = now
+	return nil
+}
+
+func (r *RedirectRepository) Delete(id int) error {
+	query := `DELETE FROM slug_redirects WHERE id = ?`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete redirect: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("redirect %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *RedirectRepository) DeleteBySlug(resourceType, slug string) error {
+	query := `DELETE FROM slug_redirects WHERE resource_type = ? AND old_slug = ?`
+	_, err := r.db.Exec(query, resourceType, slug)
+	if err != nil {
+		return fmt.Errorf("failed to delete redirect: %w", err)
+	}
+	return nil
+}
+
+func (r *RedirectRepository) DeleteByTarget(resourceType string, targetID int) error {
+	query := `DELETE FROM slug_redirects WHERE resource_type = ? AND target_id = ?`
+	_, err := r.db.Exec(query, resourceType, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete redirects: %w", err)
+	}
+	return nil
+}
+
+func (r *RedirectRepository) DeleteByWebsite(websiteID int) error {
+	query := `DELETE FROM slug_redirects WHERE website_id = ?`
+	_, err := r.db.Exec(query, websiteID)
+	if err != nil {
+		return fmt.Errorf("failed to delete redirects: %w", err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRedirect(row rowScanner) (*models.Redirect, error) {
+	redirect := &models.Redirect{}
+	err := row.Scan(
+		&redirect.ID, &redirect.ResourceType, &redirect.WebsiteID, &redirect.OldSlug,
+		&redirect.TargetID, &redirect.TargetSlug, &redirect.StatusCode, &redirect.IsManual,
+		&redirect.CreatedAt, &redirect.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return redirect, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SystemConfigRepository stores the runtime system settings as key/value
+// rows in system_config.
+type SystemConfigRepository struct {
+	db DBTX
+}
+
+func NewSystemConfigRepository(db DBTX) *SystemConfigRepository {
+	return &SystemConfigRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *SystemConfigRepository) WithTx(tx *sql.Tx) *SystemConfigRepository {
+	return &SystemConfigRepository{db: tx}
+}
+
+// GetAll returns the stored settings by key.
+func (r *SystemConfigRepository) GetAll() (map[string]string, error) {
+	rows, err := r.db.Query(`SELECT key, value FROM system_config ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system config: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan system config: %w", err)
+		}
+		values[key] = value
+	}
+	return values, rows.Err()
+}
+
+// Set stores the value of a setting, replacing any previous value.
+func (r *SystemConfigRepository) Set(key, value string) error {
+	if err := r.Delete(key); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`INSERT INTO system_config (key, value) VALUES (?, ?)`, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to save system config: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the stored value of a setting.
+func (r *SystemConfigRepository) Delete(key string) error {
+	_, err := r.db.Exec(`DELETE FROM system_config WHERE key = ?`, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete system config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type TagRepository struct {
+	db DBTX
+}
+
+func NewTagRepository(db DBTX) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *TagRepository) WithTx(tx *sql.Tx) *TagRepository {
+	return &TagRepository{db: tx}
+}
+
+// GetByWebsiteID lists the tags of a website with the number of pages using
+// each of them; pages in the trash are not counted.
+func (r *TagRepository) GetByWebsiteID(websiteID int) ([]*models.Tag, error) {
+	query := `
+		SELECT t.id, t.website_id, t.name, COUNT(p.id), t.created_at
+		FROM tags t
+		LEFT JOIN page_tags pt ON pt.tag_id = t.id
+		LEFT JOIN pages p ON p.id = pt.page_id AND p.deleted_at IS NULL
+		WHERE t.website_id = ?
+		GROUP BY t.id
+		ORDER BY t.name
+	`
+	rows, err := r.db.Query(query, websiteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		tag := &models.Tag{}
+		err := rows.Scan(&tag.ID, &tag.WebsiteID, &tag.Name, &tag.PageCount, &tag.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) GetByName(websiteID int, name string) (*models.Tag, error) {
+	query := `
+		SELECT t.id, t.website_id, t.name,
+			(SELECT COUNT(*) FROM page_tags pt JOIN pages p ON p.id = pt.page_id
+				WHERE pt.tag_id = t.id AND p.deleted_at IS NULL), t.created_at
+		FROM tags t WHERE t.website_id = ? AND t.name = ?
+	`
+	tag := &models.Tag{}
+	err := r.db.QueryRow(query, websiteID, name).Scan(&tag.ID, &tag.WebsiteID, &tag.Name, &tag.PageCount, &tag.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tag %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return tag, nil
+}
+
+// Rename changes the name of a tag on every page that uses it.
+func (r *TagRepository) Rename(id int, name string) error {
+	if err := r.touchPages(id); err != nil {
+		return err
+	}
+
+	query := `UPDATE tags SET name = ? WHERE id = ?`
+	result, err := r.db.Exec(query, name, id)
+	if err != nil {
+		return fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// Merge moves every page tagged with source to target and deletes source.
+// Pages that already carry target keep their existing position for it.
+func (r *TagRepository) Merge(sourceID, targetID int) error {
+	if err := r.touchPages(sourceID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT OR IGNORE INTO page_tags (page_id, tag_id, position)
+		SELECT page_id, ?, position FROM page_tags WHERE tag_id = ?
+	`
+	if _, err := r.db.Exec(query, targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to merge tag: %w", err)
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM page_tags WHERE tag_id = ?`, sourceID); err != nil {
+		return fmt.Errorf("failed to merge tag: %w", err)
+	}
+	if _, err := r.db.Exec(`DELETE FROM tags WHERE id = ?`, sourceID); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// Create adds a tag without attaching it to any page.
+func (r *TagRepository) Create(tag *models.Tag) error {
+	query := `INSERT INTO tags (website_id, name, created_at) VALUES (?, ?, ?)`
+	now := time.Now()
+	result, err := r.db.Exec(query, tag.WebsiteID, tag.Name, now)
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get tag ID: %w", err)
+	}
+
+	tag.ID = int(id)
+	tag.CreatedAt = now
+	return nil
+}
+
+// touchPages bumps updated_at and the version of the pages carrying a tag.
+func (r *TagRepository) touchPages(tagID int) error {
+	query := `
+		UPDATE pages SET updated_at = ?, version = version + 1
+		WHERE id IN (SELECT page_id FROM page_tags WHERE tag_id = ?)
+	`
+	if _, err := r.db.Exec(query, time.Now(), tagID); err != nil {
+		return fmt.Errorf("failed to update tagged pages: %w", err)
+	}
+	return nil
+}
+
+// replacePageTags links a page to tags by name, in order, creating missing
+// tags in the website and dropping tags no page uses anymore.
+func replacePageTags(db DBTX, websiteID, pageID int, tags []string) error {
+	if _, err := db.Exec(`DELETE FROM page_tags WHERE page_id = ?`, pageID); err != nil {
+		return fmt.Errorf("failed to delete page tags: %w", err)
+	}
+
+	now := time.Now()
+	for position, name := range tags {
+		_, err := db.Exec(`INSERT OR IGNORE INTO tags (website_id, name, created_at) VALUES (?, ?, ?)`,
+			websiteID, name, now)
+		if err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+
+		query := `
+			INSERT OR IGNORE INTO page_tags (page_id, tag_id, position)
+			SELECT ?, id, ? FROM tags WHERE website_id = ? AND name = ?
+		`
+		if _, err := db.Exec(query, pageID, position, websiteID, name); err != nil {
+			return fmt.Errorf("failed to tag page: %w", err)
+		}
+	}
+
+	query := `
+		DELETE FROM tags WHERE website_id = ?
+			AND NOT EXISTS (SELECT 1 FROM page_tags pt WHERE pt.tag_id = tags.id)
+	`
+	if _, err := db.Exec(query, websiteID); err != nil {
+		return fmt.Errorf("failed to delete unused tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+// TrashRepository lists the websites, pages and users in the trash.
+type TrashRepository struct {
+	db DBTX
+}
+
+func NewTrashRepository(db DBTX) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *TrashRepository) WithTx(tx *sql.Tx) *TrashRepository {
+	return &TrashRepository{db: tx}
+}
+
+// GetAll lists the items in the trash of one type, or of every type when
+// itemType is empty, most recently deleted first.
+func (r *TrashRepository) GetAll(itemType string) ([]*models.TrashItem, error) {
+	return r.list(itemType, "")
+}
+
+// GetDeletedBefore lists the items of every type deleted before cutoff,
+// oldest first.
+func (r *TrashRepository) GetDeletedBefore(cutoff time.Time) ([]*models.TrashItem, error) {
+	items, err := r.list("", ` AND deleted_at < ?`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.Before(items[j].DeletedAt) })
+	return items, nil
+}
+
+// trashQueries select the items of each type; the columns are type, id,
+// name, slug, email, website ID and deletion time. Pages of websites in the
+// trash belong to their website and are left out.
+var trashQueries = []struct {
+	itemType string
+	query    string
+}{
+	{models.TrashTypeWebsite, `
+		SELECT id, name, slug, '', NULL, deleted_at FROM websites
+		WHERE deleted_at IS NOT NULL`},
+	{models.TrashTypePage, `
+		SELECT id, title, slug, '', website_id, deleted_at FROM pages
+		WHERE deleted_at IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM websites w WHERE w.id = pages.website_id AND w.deleted_at IS NOT NULL
+		)`},
+	{models.TrashTypeUser, `
+		SELECT id, name, '', email, NULL, deleted_at FROM users
+		WHERE deleted_at IS NOT NULL`},
+}
+
+func (r *TrashRepository) list(itemType, condition string, args ...interface{}) ([]*models.TrashItem, error) {
+	items := []*models.TrashItem{}
+	for _, q := range trashQueries {
+		if itemType != "" && itemType != q.itemType {
+			continue
+		}
+
+		rows, err := r.db.Query(q.query+condition, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trash: %w", err)
+		}
+		for rows.Next() {
+			item := &models.TrashItem{Type: q.itemType}
+			if err := rows.Scan(&item.ID, &item.Name, &item.Slug, &item.Email, &item.WebsiteID, &item.DeletedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan trash item: %w", err)
+			}
+			items = append(items, item)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trash: %w", err)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
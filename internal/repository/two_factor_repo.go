@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+type TwoFactorRepository struct {
+	db DBTX
+}
+
+func NewTwoFactorRepository(db DBTX) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *TwoFactorRepository) WithTx(tx *sql.Tx) *TwoFactorRepository {
+	return &TwoFactorRepository{db: tx}
+}
+
+// GetTOTP returns the TOTP enrollment of a user, pending or enabled.
+func (r *TwoFactorRepository) GetTOTP(userID int) (*models.UserTOTP, error) {
+	query := `SELECT user_id, secret, enabled_at, last_step, created_at FROM user_totp WHERE user_id = ?`
+	totp := &models.UserTOTP{}
+	err := r.db.QueryRow(query, userID).Scan(&totp.UserID, &totp.Secret, &totp.EnabledAt, &totp.LastStep, &totp.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("TOTP enrollment %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get TOTP enrollment: %w", err)
+	}
+	return totp, nil
+}
+
+// SavePendingTOTP starts an enrollment with a new secret, replacing any
+// pending one. It fails with ErrVersionConflict if the enrollment was
+// enabled meanwhile.
+func (r *TwoFactorRepository) SavePendingTOTP(totp *models.UserTOTP) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, last_step, created_at) VALUES (?, ?, 0, ?)
+		ON CONFLICT (user_id) DO UPDATE SET secret = excluded.secret, last_step = 0, created_at = excluded.created_at
+		WHERE user_totp.enabled_at IS NULL
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, totp.UserID, totp.Secret, now)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP enrollment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	totp.EnabledAt = nil
+	totp.LastStep = 0
+	totp.CreatedAt = now
+	return nil
+}
+
+// EnableTOTP completes a pending enrollment, recording the step of the code
+// that confirmed it.
+func (r *TwoFactorRepository) EnableTOTP(userID int, step int64) error {
+	query := `UPDATE user_totp SET enabled_at = ?, last_step = ? WHERE user_id = ? AND enabled_at IS NULL`
+	result, err := r.db.Exec(query, time.Now(), step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending TOTP enrollment %w", ErrNotFound)
+	}
+	return nil
+}
+
+// UseTOTPStep records that the code of a step was used. It fails with
+// ErrNotFound if that step or a later one was used already, so each code
+// works once even under concurrent requests.
+func (r *TwoFactorRepository) UseTOTPStep(userID int, step int64) error {
+	query := `UPDATE user_totp SET last_step = ? WHERE user_id = ? AND last_step < ? AND enabled_at IS NOT NULL`
+	result, err := r.db.Exec(query, step, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to update TOTP enrollment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("unused TOTP step %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) DeleteTOTP(userID int) error {
+	query := `DELETE FROM user_totp WHERE user_id = ?`
+	_, err := r.db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete TOTP enrollment: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes stores a new set of recovery code hashes for a
+// user, invalidating the previous set.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	if err := r.DeleteRecoveryCodes(userID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`
+	now := time.Now()
+	for _, codeHash := range codeHashes {
+		if _, err := r.db.Exec(query, userID, codeHash, now); err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of a user as used.
+func (r *TwoFactorRepository) UseRecoveryCode(userID int, codeHash string) error {
+	query := `UPDATE user_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	result, err := r.db.Exec(query, time.Now(), userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("recovery code %w", ErrNotFound)
+	}
+	return nil
+}
+
+// CountRecoveryCodes returns how many unused recovery codes a user has.
+func (r *TwoFactorRepository) CountRecoveryCodes(userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	var count int
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func (r *TwoFactorRepository) DeleteRecoveryCodes(userID int) error {
+	query := `DELETE FROM user_recovery_codes WHERE user_id = ?`
+	_, err := r.db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return nil
+}
+
+// EnrollmentRequired reports whether a user has a role that requires
+// two-factor authentication without having enabled it.
+func (r *TwoFactorRepository) EnrollmentRequired(userID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_roles
+			JOIN roles ON roles.id = user_roles.role_id
+			WHERE user_roles.user_id = ? AND roles.require_two_factor
+		) AND NOT EXISTS (
+			SELECT 1 FROM user_totp WHERE user_id = ? AND enabled_at IS NOT NULL
+		)
+	`
+	var required bool
+	if err := r.db.QueryRow(query, userID, userID).Scan(&required); err != nil {
+		return false, fmt.Errorf("failed to check two-factor requirement: %w", err)
+	}
+	return required, nil
+}
+
+// RoleRequiresTwoFactor reports whether any role of a user requires
+// two-factor authentication.
+func (r *TwoFactorRepository) RoleRequiresTwoFactor(userID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_roles
+			JOIN roles ON roles.id = user_roles.role_id
+			WHERE user_roles.user_id = ? AND roles.require_two_factor
+		)
+	`
+	var required bool
+	if err := r.db.QueryRow(query, userID).Scan(&required); err != nil {
+		return false, fmt.Errorf("failed to check two-factor requirement: %w", err)
+	}
+	return required, nil
+}
+
+func (r *TwoFactorRepository) CreateChallenge(challenge *models.LoginChallenge) error {
+	query := `
+		INSERT INTO login_challenges (user_id, token_hash, attempts, expires_at, created_at)
+		VALUES (?, ?, 0, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, challenge.UserID, challenge.TokenHash, challenge.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create login challenge: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get login challenge ID: %w", err)
+	}
+
+	challenge.ID = int(id)
+	challenge.CreatedAt = now
+	return nil
+}
+
+// GetChallengeByHash returns the unexpired login challenge with a token
+// hash.
+func (r *TwoFactorRepository) GetChallengeByHash(tokenHash string) (*models.LoginChallenge, error) {
+	query := `
+		SELECT id, user_id, token_hash, attempts, expires_at, created_at
+		FROM login_challenges WHERE token_hash = ? AND expires_at > ?
+	`
+	challenge := &models.LoginChallenge{}
+	err := r.db.QueryRow(query, tokenHash, time.Now()).Scan(
+		&challenge.ID, &challenge.UserID, &challenge.TokenHash, &challenge.Attempts,
+		&challenge.ExpiresAt, &challenge.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("login challenge %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get login challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// CountChallengeAttempt records a wrong code for a challenge.
+func (r *TwoFactorRepository) CountChallengeAttempt(id int) error {
+	query := `UPDATE login_challenges SET attempts = attempts + 1 WHERE id = ?`
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to update login challenge: %w", err)
+	}
+	return nil
+}
+
+// DeleteChallenge consumes a challenge. It fails with ErrNotFound if the
+// challenge is already gone, e.g. used by a concurrent request.
+func (r *TwoFactorRepository) DeleteChallenge(id int) error {
+	query := `DELETE FROM login_challenges WHERE id = ?`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete login challenge: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("login challenge %w", ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteUserChallenges removes the pending login challenges of a user.
+func (r *TwoFactorRepository) DeleteUserChallenges(userID int) error {
+	query := `DELETE FROM login_challenges WHERE user_id = ?`
+	_, err := r.db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete login challenges: %w", err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) DeleteExpiredChallenges() error {
+	query := `DELETE FROM login_challenges WHERE expires_at <= ?`
+	_, err := r.db.Exec(query, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired login challenges: %w", err)
+	}
+	return nil
+}
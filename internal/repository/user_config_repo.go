@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// UserConfigRepository stores per-user settings as key/value rows in
+// user_config.
+type UserConfigRepository struct {
+	db DBTX
+}
+
+func NewUserConfigRepository(db DBTX) *UserConfigRepository {
+	return &UserConfigRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *UserConfigRepository) WithTx(tx *sql.Tx) *UserConfigRepository {
+	return &UserConfigRepository{db: tx}
+}
+
+// GetByUser returns the settings of a user by key.
+func (r *UserConfigRepository) GetByUser(userID int) (map[string]string, error) {
+	query := `SELECT key, value FROM user_config WHERE user_id = ? ORDER BY id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user config: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan user config: %w", err)
+		}
+		values[key] = value
+	}
+	return values, rows.Err()
+}
+
+// Replace sets the settings of a user to values, removing all other keys.
+// Run it in a transaction so readers never see a partial set.
+func (r *UserConfigRepository) Replace(userID int, values map[string]string) error {
+	_, err := r.db.Exec(`DELETE FROM user_config WHERE user_id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user config: %w", err)
+	}
+
+	query := `INSERT INTO user_config (user_id, key, value) VALUES (?, ?, ?)`
+	for key, value := range values {
+		if _, err := r.db.Exec(query, userID, key, value); err != nil {
+			return fmt.Errorf("failed to save user config: %w", err)
+		}
+	}
+	return nil
+}
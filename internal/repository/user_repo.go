@@ -9,20 +9,25 @@ import (
 )
 
 type UserRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
+func NewUserRepository(db DBTX) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *UserRepository) WithTx(tx *sql.Tx) *UserRepository {
+	return &UserRepository{db: tx}
+}
+
 func (r *UserRepository) Create(user *models.User) error {
 	query := `
-		INSERT INTO users (email, password_hash, name, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO users (email, password_hash, name, must_change_password, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
-	result, err := r.db.Exec(query, user.Email, user.PasswordHash, user.Name, now, now)
+	result, err := r.db.Exec(query, user.Email, user.PasswordHash, user.Name, user.MustChangePassword, now, now)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -35,22 +40,23 @@ func (r *UserRepository) Create(user *models.User) error {
 	user.ID = int(id)
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
 	return nil
 }
 
 func (r *UserRepository) GetByID(id int) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, created_at, updated_at
-		FROM users WHERE id = ?
+		SELECT id, email, password_hash, name, must_change_password, created_at, updated_at, version
+		FROM users WHERE id = ? AND deleted_at IS NULL
 	`
 	user := &models.User{}
 	err := r.db.QueryRow(query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.MustChangePassword,
+		&user.CreatedAt, &user.UpdatedAt, &user.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -59,17 +65,17 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, created_at, updated_at
-		FROM users WHERE email = ?
+		SELECT id, email, password_hash, name, must_change_password, created_at, updated_at, version
+		FROM users WHERE email = ? AND deleted_at IS NULL
 	`
 	user := &models.User{}
 	err := r.db.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.MustChangePassword,
+		&user.CreatedAt, &user.UpdatedAt, &user.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -78,8 +84,8 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 func (r *UserRepository) GetAll() ([]*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, created_at, updated_at
-		FROM users ORDER BY created_at DESC
+		SELECT id, email, password_hash, name, must_change_password, created_at, updated_at, version
+		FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC
 	`
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -91,8 +97,8 @@ func (r *UserRepository) GetAll() ([]*models.User, error) {
 	for rows.Next() {
 		user := &models.User{}
 		err := rows.Scan(
-			&user.ID, &user.Email, &user.PasswordHash, &user.Name,
-			&user.CreatedAt, &user.UpdatedAt,
+			&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.MustChangePassword,
+			&user.CreatedAt, &user.UpdatedAt, &user.Version,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
@@ -102,30 +108,114 @@ func (r *UserRepository) GetAll() ([]*models.User, error) {
 	return users, nil
 }
 
+// Update saves the user if the stored version still equals user.Version,
+// which is then incremented.
 func (r *UserRepository) Update(id int, user *models.User) error {
 	query := `
-		UPDATE users SET email = ?, name = ?, updated_at = ?
-		WHERE id = ?
+		UPDATE users SET email = ?, name = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
 	now := time.Now()
-	result, err := r.db.Exec(query, user.Email, user.Name, now, id)
+	result, err := r.db.Exec(query, user.Email, user.Name, now, id, user.Version)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
+	if err := checkVersionedUpdate(r.db, result, "users", "user", id); err != nil {
+		return err
+	}
+
+	user.UpdatedAt = now
+	user.Version++
+	return nil
+}
+
+// UpdatePassword replaces the password hash and the must-change flag of a
+// user regardless of its version, which is incremented.
+func (r *UserRepository) UpdatePassword(user *models.User) error {
+	query := `
+		UPDATE users SET password_hash = ?, must_change_password = ?, updated_at = ?, version = version + 1
+		WHERE id = ?
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, user.PasswordHash, user.MustChangePassword, now, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+
+	user.UpdatedAt = now
+	user.Version++
+	return nil
+}
 
+// SetMustChangePassword sets or clears the flag forcing a user to change
+// the password, incrementing the user's version.
+func (r *UserRepository) SetMustChangePassword(user *models.User, mustChange bool) error {
+	query := `
+		UPDATE users SET must_change_password = ?, updated_at = ?, version = version + 1
+		WHERE id = ?
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, mustChange, now, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return fmt.Errorf("user %w", ErrNotFound)
 	}
 
+	user.MustChangePassword = mustChange
 	user.UpdatedAt = now
+	user.Version++
 	return nil
 }
 
+// GetTrashedByID returns a user in the trash.
+func (r *UserRepository) GetTrashedByID(id int) (*models.User, error) {
+	query := `
+		SELECT id, email, password_hash, name, must_change_password, created_at, updated_at, version
+		FROM users WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	user := &models.User{}
+	err := r.db.QueryRow(query, id).Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.MustChangePassword,
+		&user.CreatedAt, &user.UpdatedAt, &user.Version,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// Trash moves a user to the trash; the email becomes free.
+func (r *UserRepository) Trash(id int, deletedAt time.Time) error {
+	query := `UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	return updateOne(r.db, "user", query, deletedAt, id)
+}
+
+// Restore takes a user out of the trash.
+func (r *UserRepository) Restore(id int) error {
+	query := `UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	return updateOne(r.db, "user", query, id)
+}
+
+// Delete removes a user for good, whether or not it is in the trash.
 func (r *UserRepository) Delete(id int) error {
 	query := `DELETE FROM users WHERE id = ?`
 	result, err := r.db.Exec(query, id)
@@ -139,7 +229,7 @@ func (r *UserRepository) Delete(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return fmt.Errorf("user %w", ErrNotFound)
 	}
 
 	return nil
@@ -148,11 +238,13 @@ func (r *UserRepository) Delete(id int) error {
 // Session management methods
 func (r *UserRepository) CreateSession(session *models.UserSession) error {
 	query := `
-		INSERT INTO user_sessions (user_id, session_token, expires_at, created_at)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO user_sessions (user_id, token_hash, user_agent, ip_address, last_seen_at, expires_at,
+			absolute_expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
-	result, err := r.db.Exec(query, session.UserID, session.SessionToken, session.ExpiresAt, now)
+	result, err := r.db.Exec(query, session.UserID, session.TokenHash, session.UserAgent, session.IPAddress,
+		now, session.ExpiresAt, session.AbsoluteExpiresAt, now)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
 	}
@@ -163,19 +255,21 @@ func (r *UserRepository) CreateSession(session *models.UserSession) error {
 	}
 
 	session.ID = int(id)
+	session.LastSeenAt = now
 	session.CreatedAt = now
 	return nil
 }
 
-func (r *UserRepository) GetSessionByToken(token string) (*models.UserSession, error) {
+// GetSessionByTokenHash returns the unexpired session with a token hash.
+func (r *UserRepository) GetSessionByTokenHash(tokenHash string) (*models.UserSession, error) {
 	query := `
-		SELECT id, user_id, session_token, expires_at, created_at
-		FROM user_sessions WHERE session_token = ? AND expires_at > ?
+		SELECT id, user_id, token_hash, user_agent, ip_address, last_seen_at, expires_at, absolute_expires_at, created_at
+		FROM user_sessions WHERE token_hash = ? AND expires_at > ?
 	`
 	session := &models.UserSession{}
-	err := r.db.QueryRow(query, token, time.Now()).Scan(
-		&session.ID, &session.UserID, &session.SessionToken,
-		&session.ExpiresAt, &session.CreatedAt,
+	err := r.db.QueryRow(query, tokenHash, time.Now()).Scan(
+		&session.ID, &session.UserID, &session.TokenHash, &session.UserAgent, &session.IPAddress,
+		&session.LastSeenAt, &session.ExpiresAt, &session.AbsoluteExpiresAt, &session.CreatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -186,15 +280,105 @@ func (r *UserRepository) GetSessionByToken(token string) (*models.UserSession, e
 	return session, nil
 }
 
-func (r *UserRepository) DeleteSession(token string) error {
-	query := `DELETE FROM user_sessions WHERE session_token = ?`
-	_, err := r.db.Exec(query, token)
+// GetSessionsByUser returns the unexpired sessions of a user, most recently
+// used first.
+func (r *UserRepository) GetSessionsByUser(userID int) ([]*models.UserSession, error) {
+	query := `
+		SELECT id, user_id, token_hash, user_agent, ip_address, last_seen_at, expires_at, absolute_expires_at, created_at
+		FROM user_sessions WHERE user_id = ? AND expires_at > ?
+		ORDER BY last_seen_at DESC, id DESC
+	`
+	rows, err := r.db.Query(query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*models.UserSession{}
+	for rows.Next() {
+		session := &models.UserSession{}
+		err := rows.Scan(
+			&session.ID, &session.UserID, &session.TokenHash, &session.UserAgent, &session.IPAddress,
+			&session.LastSeenAt, &session.ExpiresAt, &session.AbsoluteExpiresAt, &session.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// TouchSession records activity on a session from a client IP and moves
+// its expiry.
+func (r *UserRepository) TouchSession(session *models.UserSession) error {
+	query := `UPDATE user_sessions SET last_seen_at = ?, ip_address = ?, expires_at = ? WHERE id = ?`
+	_, err := r.db.Exec(query, session.LastSeenAt, session.IPAddress, session.ExpiresAt, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) DeleteSessionByTokenHash(tokenHash string) error {
+	query := `DELETE FROM user_sessions WHERE token_hash = ?`
+	_, err := r.db.Exec(query, tokenHash)
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 	return nil
 }
 
+// DeleteUserSession removes a session of a user by ID.
+func (r *UserRepository) DeleteUserSession(userID, id int) error {
+	query := `DELETE FROM user_sessions WHERE id = ? AND user_id = ?`
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("session %w", ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteOtherSessions removes every session of a user except one and
+// returns how many were removed.
+func (r *UserRepository) DeleteOtherSessions(userID, keepID int) (int, error) {
+	query := `DELETE FROM user_sessions WHERE user_id = ? AND id != ?`
+	result, err := r.db.Exec(query, userID, keepID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// DeleteSessionsByUser removes every session of a user and returns how
+// many were removed.
+func (r *UserRepository) DeleteSessionsByUser(userID int) (int, error) {
+	query := `DELETE FROM user_sessions WHERE user_id = ?`
+	result, err := r.db.Exec(query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
 func (r *UserRepository) DeleteExpiredSessions() error {
 	query := `DELETE FROM user_sessions WHERE expires_at <= ?`
 	_, err := r.db.Exec(query, time.Now())
@@ -203,3 +387,225 @@ func (r *UserRepository) DeleteExpiredSessions() error {
 	}
 	return nil
 }
+
+// GetRoleNames returns the names of the roles assigned to a user.
+func (r *UserRepository) GetRoleNames(userID int) ([]string, error) {
+	query := `
+		SELECT roles.name FROM roles
+		JOIN user_roles ON user_roles.role_id = roles.id
+		WHERE user_roles.user_id = ?
+		ORDER BY roles.name
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+const roleColumns = `id, name, description, permissions, require_two_factor, created_at, updated_at`
+
+func scanRole(row interface{ Scan(...interface{}) error }) (*models.Role, error) {
+	role := &models.Role{}
+	err := row.Scan(
+		&role.ID, &role.Name, &role.Description, &role.Permissions, &role.RequireTwoFactor,
+		&role.CreatedAt, &role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// GetRoles returns all roles by name.
+func (r *UserRepository) GetRoles() ([]*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles ORDER BY name`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []*models.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// GetRoleByID returns the role with an ID.
+func (r *UserRepository) GetRoleByID(id int) (*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE id = ?`
+	role, err := scanRole(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("role %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+// GetRoleByName returns the role with a name.
+func (r *UserRepository) GetRoleByName(name string) (*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE name = ?`
+	role, err := scanRole(r.db.QueryRow(query, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("role %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+// SetRoleRequireTwoFactor turns the two-factor requirement of a role on or
+// off.
+func (r *UserRepository) SetRoleRequireTwoFactor(role *models.Role, required bool) error {
+	query := `UPDATE roles SET require_two_factor = ?, updated_at = ? WHERE id = ?`
+	now := time.Now()
+	result, err := r.db.Exec(query, required, now, role.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("role %w", ErrNotFound)
+	}
+
+	role.RequireTwoFactor = required
+	role.UpdatedAt = now
+	return nil
+}
+
+// AddRole grants a role to a user; granting a role twice is a no-op.
+func (r *UserRepository) AddRole(userID, roleID int) error {
+	query := `INSERT INTO user_roles (user_id, role_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	_, err := r.db.Exec(query, userID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to add role: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) RemoveRole(userID, roleID int) error {
+	query := `DELETE FROM user_roles WHERE user_id = ? AND role_id = ?`
+	_, err := r.db.Exec(query, userID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+	return nil
+}
+
+// Password reset token methods
+func (r *UserRepository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, token.UserID, token.TokenHash, token.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get password reset token ID: %w", err)
+	}
+
+	token.ID = int(id)
+	token.CreatedAt = now
+	return nil
+}
+
+// GetPasswordResetTokenByHash returns an unused, unexpired reset token.
+func (r *UserRepository) GetPasswordResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+	`
+	token := &models.PasswordResetToken{}
+	err := r.db.QueryRow(query, tokenHash, time.Now()).Scan(
+		&token.ID, &token.UserID, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("password reset token %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+	return token, nil
+}
+
+// MarkPasswordResetTokenUsed consumes a reset token. A token that has
+// already been used is reported as not found, so it works only once even
+// under concurrent requests.
+func (r *UserRepository) MarkPasswordResetTokenUsed(id int) error {
+	query := `UPDATE password_reset_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to use password reset token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("password reset token %w", ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteUnusedPasswordResetTokens revokes the outstanding reset tokens of a
+// user.
+func (r *UserRepository) DeleteUnusedPasswordResetTokens(userID int) error {
+	query := `DELETE FROM password_reset_tokens WHERE user_id = ? AND used_at IS NULL`
+	_, err := r.db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+	return nil
+}
+
+// Activity log methods
+func (r *UserRepository) CreateLog(entry *models.UserLog) error {
+	query := `
+		INSERT INTO user_logs (user_id, activity_type, details, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.db.Exec(query, entry.UserID, entry.ActivityType, entry.Details, now)
+	if err != nil {
+		return fmt.Errorf("failed to create user log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get user log ID: %w", err)
+	}
+
+	entry.ID = int(id)
+	entry.CreatedAt = now
+	return nil
+}
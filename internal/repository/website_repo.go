@@ -9,13 +9,18 @@ import (
 )
 
 type WebsiteRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewWebsiteRepository(db *sql.DB) *WebsiteRepository {
+func NewWebsiteRepository(db DBTX) *WebsiteRepository {
 	return &WebsiteRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *WebsiteRepository) WithTx(tx *sql.Tx) *WebsiteRepository {
+	return &WebsiteRepository{db: tx}
+}
+
 func (r *WebsiteRepository) Create(website *models.Website) error {
 	query := `
 		INSERT INTO websites (name, slug, description, slogan, domain, git_repo_owner, 
@@ -39,25 +44,16 @@ func (r *WebsiteRepository) Create(website *models.Website) error {
 	website.ID = int(id)
 	website.CreatedAt = now
 	website.UpdatedAt = now
+	website.Version = 1
 	return nil
 }
 
 func (r *WebsiteRepository) GetByID(id int) (*models.Website, error) {
-	query := `
-		SELECT id, name, slug, description, slogan, domain, git_repo_owner, 
-			git_repo_name, git_repo_branch, git_api_token, config, language_code, created_at, updated_at
-		FROM websites WHERE id = ?
-	`
-	website := &models.Website{}
-	err := r.db.QueryRow(query, id).Scan(
-		&website.ID, &website.Name, &website.Slug, &website.Description,
-		&website.Slogan, &website.Domain, &website.GitRepoOwner, &website.GitRepoName,
-		&website.GitRepoBranch, &website.GitAPIToken, &website.Config, &website.LanguageCode,
-		&website.CreatedAt, &website.UpdatedAt,
-	)
+	query := websiteSelect + `WHERE id = ? AND deleted_at IS NULL`
+	website, err := scanWebsite(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("website not found")
+			return nil, fmt.Errorf("website %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get website: %w", err)
 	}
@@ -65,21 +61,23 @@ func (r *WebsiteRepository) GetByID(id int) (*models.Website, error) {
 }
 
 func (r *WebsiteRepository) GetBySlug(slug string) (*models.Website, error) {
-	query := `
-		SELECT id, name, slug, description, slogan, domain, git_repo_owner, 
-			git_repo_name, git_repo_branch, git_api_token, config, language_code, created_at, updated_at
-		FROM websites WHERE slug = ?
-	`
-	website := &models.Website{}
-	err := r.db.QueryRow(query, slug).Scan(
-		&website.ID, &website.Name, &website.Slug, &website.Description,
-		&website.Slogan, &website.Domain, &website.GitRepoOwner, &website.GitRepoName,
-		&website.GitRepoBranch, &website.GitAPIToken, &website.Config, &website.LanguageCode,
-		&website.CreatedAt, &website.UpdatedAt,
-	)
+	query := websiteSelect + `WHERE slug = ? AND deleted_at IS NULL`
+	website, err := scanWebsite(r.db.QueryRow(query, slug))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("website not found")
+			return nil, fmt.Errorf("website %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get website: %w", err)
+	}
+	return website, nil
+}
+
+func (r *WebsiteRepository) GetByName(name string) (*models.Website, error) {
+	query := websiteSelect + `WHERE name = ? AND deleted_at IS NULL`
+	website, err := scanWebsite(r.db.QueryRow(query, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("website %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get website: %w", err)
 	}
@@ -87,11 +85,7 @@ func (r *WebsiteRepository) GetBySlug(slug string) (*models.Website, error) {
 }
 
 func (r *WebsiteRepository) GetAll() ([]*models.Website, error) {
-	query := `
-		SELECT id, name, slug, description, slogan, domain, git_repo_owner, 
-			git_repo_name, git_repo_branch, git_api_token, config, language_code, created_at, updated_at
-		FROM websites ORDER BY created_at DESC
-	`
+	query := websiteSelect + `WHERE deleted_at IS NULL ORDER BY created_at DESC`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get websites: %w", err)
@@ -100,13 +94,7 @@ func (r *WebsiteRepository) GetAll() ([]*models.Website, error) {
 
 	var websites []*models.Website
 	for rows.Next() {
-		website := &models.Website{}
-		err := rows.Scan(
-			&website.ID, &website.Name, &website.Slug, &website.Description,
-			&website.Slogan, &website.Domain, &website.GitRepoOwner, &website.GitRepoName,
-			&website.GitRepoBranch, &website.GitAPIToken, &website.Config, &website.LanguageCode,
-			&website.CreatedAt, &website.UpdatedAt,
-		)
+		website, err := scanWebsite(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan website: %w", err)
 		}
@@ -115,35 +103,116 @@ func (r *WebsiteRepository) GetAll() ([]*models.Website, error) {
 	return websites, nil
 }
 
+// GetTrashedByID returns a website in the trash.
+func (r *WebsiteRepository) GetTrashedByID(id int) (*models.Website, error) {
+	query := websiteSelect + `WHERE id = ? AND deleted_at IS NOT NULL`
+	website, err := scanWebsite(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("website %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get website: %w", err)
+	}
+	return website, nil
+}
+
+// Update saves the website if the stored version still equals
+// website.Version, which is then incremented.
 func (r *WebsiteRepository) Update(id int, website *models.Website) error {
 	query := `
 		UPDATE websites SET name = ?, slug = ?, description = ?, slogan = ?, domain = ?, 
 			git_repo_owner = ?, git_repo_name = ?, git_repo_branch = ?, git_api_token = ?, 
-			config = ?, language_code = ?, updated_at = ?
-		WHERE id = ?
+			config = ?, language_code = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
 	now := time.Now()
 	result, err := r.db.Exec(query, website.Name, website.Slug, website.Description,
 		website.Slogan, website.Domain, website.GitRepoOwner, website.GitRepoName,
 		website.GitRepoBranch, website.GitAPIToken, website.Config, website.LanguageCode,
-		now, id)
+		now, id, website.Version)
 	if err != nil {
 		return fmt.Errorf("failed to update website: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if err := checkVersionedUpdate(r.db, result, "websites", "website", id); err != nil {
+		return err
+	}
+
+	website.UpdatedAt = now
+	website.Version++
+	return nil
+}
+
+// Trash moves a website and its pages to the trash; their slugs and the
+// website name become free. Pages already in the trash keep their own
+// deletion time.
+func (r *WebsiteRepository) Trash(id int, deletedAt time.Time) error {
+	query := `UPDATE pages SET deleted_at = ? WHERE website_id = ? AND deleted_at IS NULL`
+	if _, err := r.db.Exec(query, deletedAt, id); err != nil {
+		return fmt.Errorf("failed to trash website pages: %w", err)
+	}
+
+	query = `UPDATE websites SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	return updateOne(r.db, "website", query, deletedAt, id)
+}
+
+// Restore takes a website out of the trash together with the pages that
+// were trashed with it.
+func (r *WebsiteRepository) Restore(id int) error {
+	query := `
+		UPDATE pages SET deleted_at = NULL
+		WHERE website_id = ? AND deleted_at = (SELECT deleted_at FROM websites WHERE id = ?)
+	`
+	if _, err := r.db.Exec(query, id, id); err != nil {
+		return fmt.Errorf("failed to restore website pages: %w", err)
+	}
+
+	query = `UPDATE websites SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	return updateOne(r.db, "website", query, id)
+}
+
+// GetDeletionSummary counts the pages, assets, links, redirects and tags
+// of a website, including pages in the trash.
+func (r *WebsiteRepository) GetDeletionSummary(id int) (*models.WebsiteDeletion, error) {
+	summary := &models.WebsiteDeletion{WebsiteID: id, PagesByStatus: make(map[string]int)}
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM pages WHERE website_id = ? AND deleted_at IS NOT NULL),
+			(SELECT COALESCE(SUM(version), 0) FROM pages WHERE website_id = ?),
+			(SELECT COUNT(*) FROM page_assets WHERE page_id IN (SELECT id FROM pages WHERE website_id = ?)),
+			(SELECT COUNT(*) FROM page_links WHERE website_id = ?),
+			(SELECT COUNT(*) FROM slug_redirects WHERE website_id = ?),
+			(SELECT COUNT(*) FROM tags WHERE website_id = ?)
+	`
+	err := r.db.QueryRow(query, id, id, id, id, id, id).Scan(&summary.TrashedPages, &summary.PageVersions,
+		&summary.Assets, &summary.Links, &summary.Redirects, &summary.Tags)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to count website content: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("website not found")
+	rows, err := r.db.Query(`
+		SELECT status, COUNT(*) FROM pages WHERE website_id = ? AND deleted_at IS NULL GROUP BY status
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count website pages: %w", err)
 	}
+	defer rows.Close()
 
-	website.UpdatedAt = now
-	return nil
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan page count: %w", err)
+		}
+		summary.PagesByStatus[status] = count
+		summary.Pages += count
+	}
+	return summary, rows.Err()
 }
 
+// Delete removes a website for good, whether or not it is in the trash.
+// Pages reference their website without an ON DELETE action, so callers
+// delete the pages first.
 func (r *WebsiteRepository) Delete(id int) error {
 	query := `DELETE FROM websites WHERE id = ?`
 	result, err := r.db.Exec(query, id)
@@ -157,8 +226,29 @@ func (r *WebsiteRepository) Delete(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("website not found")
+		return fmt.Errorf("website %w", ErrNotFound)
 	}
 
 	return nil
 }
+
+// websiteSelect loads websites; callers add the WHERE clause.
+const websiteSelect = `
+	SELECT id, name, slug, description, slogan, domain, git_repo_owner,
+		git_repo_name, git_repo_branch, git_api_token, config, language_code, created_at, updated_at, version
+	FROM websites
+`
+
+func scanWebsite(row rowScanner) (*models.Website, error) {
+	website := &models.Website{}
+	err := row.Scan(
+		&website.ID, &website.Name, &website.Slug, &website.Description,
+		&website.Slogan, &website.Domain, &website.GitRepoOwner, &website.GitRepoName,
+		&website.GitRepoBranch, &website.GitAPIToken, &website.Config, &website.LanguageCode,
+		&website.CreatedAt, &website.UpdatedAt, &website.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return website, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+// APITokenPrefix starts every personal API token, which tells them apart
+// from session tokens and makes leaked tokens easy to scan for.
+const APITokenPrefix = "xdt_"
+
+// apiTokenPrefixLength is how much of a token is kept to identify it
+const apiTokenPrefixLength = len(APITokenPrefix) + 8
+
+// ErrInvalidAPIToken is returned by ValidateToken for an unknown, revoked
+// or expired token.
+var ErrInvalidAPIToken = errors.New("invalid or expired API token")
+
+// APITokenService manages personal API tokens. Tokens authenticate as their
+// owner, limited to their scopes.
+type APITokenService struct {
+	tokenRepo *repository.APITokenRepository
+	userRepo  *repository.UserRepository
+	txManager *repository.TxManager
+}
+
+func NewAPITokenService(tokenRepo *repository.APITokenRepository, userRepo *repository.UserRepository,
+	txManager *repository.TxManager) *APITokenService {
+	return &APITokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		txManager: txManager,
+	}
+}
+
+// CreateToken issues a new token for a user. The token is returned only
+// here; afterwards only its prefix is known.
+func (s *APITokenService) CreateToken(userID int, req *models.CreateAPITokenRequest) (*models.CreateAPITokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, invalidField("name", "required", "cannot be empty")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, invalidField("expiresAt", "future", "must be in the future")
+	}
+
+	// Drop duplicate scopes, keeping the requested order
+	seen := make(map[string]bool)
+	scopes := []string{}
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := APITokenPrefix + secret
+	apiToken := &models.APIToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: token[:apiTokenPrefixLength],
+		TokenHash:   hashToken(token),
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.tokenRepo.WithTx(tx).Create(apiToken); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), userID, models.ActivityAPITokenCreated,
+			map[string]interface{}{"tokenId": apiToken.ID, "name": name, "scopes": scopes})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreateAPITokenResponse{APIToken: *apiToken, Token: token}, nil
+}
+
+// GetTokens lists the tokens of a user, expired ones included.
+func (s *APITokenService) GetTokens(userID int) ([]*models.APIToken, error) {
+	return s.tokenRepo.GetByUser(userID)
+}
+
+// RevokeToken deletes one of the user's own tokens; it stops working
+// immediately.
+func (s *APITokenService) RevokeToken(userID, tokenID int) error {
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.tokenRepo.WithTx(tx).DeleteUserToken(userID, tokenID); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), userID, models.ActivityAPITokenRevoked,
+			map[string]interface{}{"tokenId": tokenID})
+	})
+}
+
+// ValidateToken returns the user and the token record of an API token and
+// records its use.
+func (s *APITokenService) ValidateToken(token string, client ClientInfo) (*models.User, *models.APIToken, error) {
+	apiToken, err := s.tokenRepo.GetByHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil, ErrInvalidAPIToken
+		}
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetByID(apiToken.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= sessionTouchInterval || apiToken.LastUsedIP != client.IP {
+		// Best effort: usage tracking must not fail the request
+		if err := s.tokenRepo.Touch(apiToken.ID, client.IP, now); err == nil {
+			apiToken.LastUsedAt, apiToken.LastUsedIP = &now, client.IP
+		}
+	}
+
+	return user, apiToken, nil
+}
+
+// IsAPIToken reports whether a bearer token is a personal API token rather
+// than a session token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// ScopesAllow reports whether token scopes grant reading or, with write,
+// changing a resource such as "pages". A write scope includes reading.
+func ScopesAllow(scopes []string, resource string, write bool) bool {
+	for _, scope := range scopes {
+		if scope == resource+":write" || (!write && scope == resource+":read") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/archive"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/storage"
+)
+
+// maxImportSize caps the uncompressed size of an imported archive.
+const maxImportSize = 512 << 20
+
+// ImportConflictError is returned when an import would reuse slugs or names
+// that already exist and the conflict strategy does not resolve them.
+type ImportConflictError struct {
+	Conflicts []string
+}
+
+func (e *ImportConflictError) Error() string {
+	return fmt.Sprintf("import conflicts with existing data: %s", strings.Join(e.Conflicts, ", "))
+}
+
+type ArchiveService struct {
+	websiteRepo  *repository.WebsiteRepository
+	pageRepo     *repository.PageRepository
+	assetRepo    *repository.AssetRepository
+	linkRepo     *repository.LinkRepository
+	redirectRepo *repository.RedirectRepository
+	txManager    *repository.TxManager
+	store        storage.BlobStore
+}
+
+func NewArchiveService(websiteRepo *repository.WebsiteRepository, pageRepo *repository.PageRepository,
+	assetRepo *repository.AssetRepository, linkRepo *repository.LinkRepository,
+	redirectRepo *repository.RedirectRepository, txManager *repository.TxManager,
+	store storage.BlobStore) *ArchiveService {
+	return &ArchiveService{
+		websiteRepo:  websiteRepo,
+		pageRepo:     pageRepo,
+		assetRepo:    assetRepo,
+		linkRepo:     linkRepo,
+		redirectRepo: redirectRepo,
+		txManager:    txManager,
+		store:        store,
+	}
+}
+
+// ExportWebsite packs a website, its pages and their assets into an archive.
+// It returns the archive and a suggested file name.
+func (s *ArchiveService) ExportWebsite(id int, format string) ([]byte, string, error) {
+	website, err := s.websiteRepo.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pages, err := s.pageRepo.GetByWebsiteID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Slug < pages[j].Slug })
+
+	assets, err := s.assetRepo.GetByWebsiteID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	pageAssets := make(map[int][]*models.PageAsset)
+	for _, asset := range assets {
+		pageAssets[asset.PageID] = append(pageAssets[asset.PageID], asset)
+	}
+
+	config, err := json.Marshal(website.Config)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode config: %w", err)
+	}
+	manifest := &models.ArchiveManifest{
+		FormatVersion: models.ArchiveFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Website: models.ArchiveWebsite{
+			Name:          website.Name,
+			Slug:          website.Slug,
+			Description:   website.Description,
+			Slogan:        website.Slogan,
+			Domain:        website.Domain,
+			GitRepoOwner:  website.GitRepoOwner,
+			GitRepoName:   website.GitRepoName,
+			GitRepoBranch: website.GitRepoBranch,
+			Config:        config,
+			LanguageCode:  website.LanguageCode,
+		},
+		Pages: []string{},
+	}
+
+	var files []archive.File
+	for _, page := range pages {
+		front := &models.ArchivePage{
+			Title:              page.Title,
+			Slug:               page.Slug,
+			Description:        page.Description,
+			Status:             page.Status,
+			FreezeStatus:       page.FreezeStatus,
+			Tags:               page.Tags,
+			ScheduledPublishAt: page.ScheduledPublishAt,
+		}
+
+		for i, asset := range pageAssets[page.ID] {
+			data, err := s.store.Get(asset.BucketKey)
+			if errors.Is(err, storage.ErrNotFound) {
+				manifest.MissingAssets = append(manifest.MissingAssets, asset.BucketKey)
+				continue
+			}
+			if err != nil {
+				return nil, "", err
+			}
+
+			name := fmt.Sprintf("assets/%s/%d-%s", url.PathEscape(page.Slug), i+1, path.Base(asset.BucketKey))
+			files = append(files, archive.File{Name: name, Data: data})
+			front.Assets = append(front.Assets, models.ArchiveAsset{
+				File:      name,
+				MimeType:  asset.MimeType,
+				BucketKey: asset.BucketKey,
+			})
+		}
+
+		data, err := encodePageFile(front, page.MarkdownContent)
+		if err != nil {
+			return nil, "", err
+		}
+		name := "pages/" + url.PathEscape(page.Slug) + ".md"
+		files = append(files, archive.File{Name: name, Data: data})
+		manifest.Pages = append(manifest.Pages, name)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	files = append([]archive.File{{Name: "website.json", Data: manifestData}}, files...)
+
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, format, files); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), website.Slug + "." + format, nil
+}
+
+// importPage is a page read from an archive, before it is written.
+type importPage struct {
+	front   *models.ArchivePage
+	content string
+	assets  []models.ArchiveAsset
+	// existing is the page replaced by an overwrite import
+	existing *models.Page
+}
+
+// ImportWebsite recreates a website from an export archive. Slugs and names
+// that already exist are handled according to req.OnConflict: "fail" (the
+// default) rejects the import, "rename" imports under free slugs and
+// rewrites internal links to match, and "overwrite" replaces the website
+// with the same slug and its pages of the same slug.
+func (s *ArchiveService) ImportWebsite(data []byte, req *models.ImportWebsiteRequest) (*models.ImportReport, error) {
+	files, err := archive.Read(data, maxImportSize)
+	if err != nil {
+		return nil, invalidRequest(err.Error())
+	}
+	entries := make(map[string][]byte)
+	for _, file := range files {
+		entries[file.Name] = file.Data
+	}
+
+	manifestData, ok := entries["website.json"]
+	if !ok {
+		return nil, invalidRequest("archive has no website.json")
+	}
+	var manifest models.ArchiveManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, invalidRequest("invalid website.json: " + err.Error())
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > models.ArchiveFormatVersion {
+		return nil, invalidRequest(fmt.Sprintf("unsupported archive format version %d", manifest.FormatVersion))
+	}
+
+	site := manifest.Website
+	if site.Name == "" || site.Slug == "" {
+		return nil, invalidRequest("website name and slug are required")
+	}
+	if len(site.LanguageCode) != 2 {
+		return nil, invalidRequest("website languageCode must have 2 characters")
+	}
+	config, err := parseWebsiteConfig(site.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{}
+	pages, err := readImportPages(entries, report)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve conflicts with existing websites and pages
+	onConflict := req.OnConflict
+	if onConflict == "" {
+		onConflict = models.ImportConflictFail
+	}
+
+	websites, err := s.websiteRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var target *models.Website
+	nameTaken := func(name string) bool {
+		for _, website := range websites {
+			if website.Name == name && website != target {
+				return true
+			}
+		}
+		return false
+	}
+	slugTaken := func(slug string) bool {
+		for _, website := range websites {
+			if website.Slug == slug {
+				return true
+			}
+		}
+		return false
+	}
+
+	var conflicts []string
+	for _, website := range websites {
+		if website.Slug == site.Slug {
+			if onConflict == models.ImportConflictOverwrite {
+				target = website
+			} else {
+				conflicts = append(conflicts, "website slug "+site.Slug)
+			}
+		}
+	}
+	if nameTaken(site.Name) {
+		conflicts = append(conflicts, "website name "+site.Name)
+	}
+
+	archiveSlugs := make(map[string]bool)
+	for _, page := range pages {
+		archiveSlugs[page.front.Slug] = true
+	}
+	var renamePages []*importPage
+	for _, page := range pages {
+		existing, _ := s.pageRepo.GetBySlug(page.front.Slug)
+		if existing == nil {
+			continue
+		}
+		if target != nil && existing.WebsiteID == target.ID {
+			page.existing = existing
+			continue
+		}
+		conflicts = append(conflicts, "page slug "+page.front.Slug)
+		renamePages = append(renamePages, page)
+	}
+
+	if len(conflicts) > 0 {
+		if onConflict != models.ImportConflictRename {
+			return nil, &ImportConflictError{Conflicts: conflicts}
+		}
+
+		if slugTaken(site.Slug) {
+			site.Slug = uniqueName(site.Slug, "-%d", slugTaken)
+		}
+		if nameTaken(site.Name) {
+			site.Name = uniqueName(site.Name, " (%d)", nameTaken)
+		}
+
+		report.RenamedSlugs = make(map[string]string)
+		pageSlugTaken := func(slug string) bool {
+			if archiveSlugs[slug] {
+				return true
+			}
+			existing, _ := s.pageRepo.GetBySlug(slug)
+			return existing != nil
+		}
+		for _, page := range renamePages {
+			newSlug := uniqueName(page.front.Slug, "-%d", pageSlugTaken)
+			report.RenamedSlugs[page.front.Slug] = newSlug
+			archiveSlugs[newSlug] = true
+			page.front.Slug = newSlug
+		}
+		for _, page := range pages {
+			for oldSlug, newSlug := range report.RenamedSlugs {
+				page.content = rewriteSlugLinks(page.content, oldSlug, newSlug)
+			}
+		}
+	}
+
+	// Store asset blobs first so the database never references missing
+	// blobs; they are removed again if the transaction fails.
+	batch, err := importBatchID()
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	cleanup := func() {
+		for _, key := range written {
+			if err := s.store.Delete(key); err != nil {
+				log.Printf("Failed to remove imported blob %s: %v", key, err)
+			}
+		}
+	}
+	for _, page := range pages {
+		for i, asset := range page.assets {
+			key := fmt.Sprintf("imports/%s/%s/%s", batch, url.PathEscape(page.front.Slug), path.Base(asset.File))
+			if err := s.store.Put(key, entries[asset.File]); err != nil {
+				cleanup()
+				return nil, err
+			}
+			written = append(written, key)
+			page.assets[i].BucketKey = key
+		}
+	}
+
+	var replacedBlobs []string
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		websiteRepo := s.websiteRepo.WithTx(tx)
+		pageRepo := s.pageRepo.WithTx(tx)
+		assetRepo := s.assetRepo.WithTx(tx)
+		linkRepo := s.linkRepo.WithTx(tx)
+		redirectRepo := s.redirectRepo.WithTx(tx)
+
+		website := target
+		if website == nil {
+			website = &models.Website{GitAPIToken: req.GitAPIToken}
+		} else if req.GitAPIToken != "" {
+			website.GitAPIToken = req.GitAPIToken
+		}
+		website.Name = site.Name
+		website.Slug = site.Slug
+		website.Description = site.Description
+		website.Slogan = site.Slogan
+		website.Domain = site.Domain
+		website.GitRepoOwner = site.GitRepoOwner
+		website.GitRepoName = site.GitRepoName
+		website.GitRepoBranch = site.GitRepoBranch
+		website.Config = *config
+		website.LanguageCode = site.LanguageCode
+
+		if target != nil {
+			if err := websiteRepo.Update(website.ID, website); err != nil {
+				return err
+			}
+			report.Overwritten = true
+		} else {
+			if err := websiteRepo.Create(website); err != nil {
+				return err
+			}
+			if err := redirectRepo.DeleteBySlug(models.RedirectTypeWebsite, website.Slug); err != nil {
+				return err
+			}
+		}
+		report.Website = website
+
+		for _, item := range pages {
+			page := item.existing
+			if page == nil {
+				page = &models.Page{WebsiteID: website.ID}
+			}
+			if page.Status != item.front.Status {
+				page.LastStatusChangeAt = time.Now()
+			}
+			page.Title = item.front.Title
+			page.Slug = item.front.Slug
+			page.Description = item.front.Description
+			page.MarkdownContent = item.content
+			page.Status = item.front.Status
+			page.FreezeStatus = item.front.FreezeStatus
+			page.ScheduledPublishAt = item.front.ScheduledPublishAt
+			page.Tags = normalizeTags(item.front.Tags)
+
+			if item.existing != nil {
+				if err := pageRepo.Update(page.ID, page); err != nil {
+					return err
+				}
+				old, err := assetRepo.GetByPageID(page.ID)
+				if err != nil {
+					return err
+				}
+				for _, asset := range old {
+					replacedBlobs = append(replacedBlobs, asset.BucketKey)
+				}
+				if err := assetRepo.DeleteByPageID(page.ID); err != nil {
+					return err
+				}
+				report.PagesUpdated++
+			} else {
+				if err := pageRepo.Create(page); err != nil {
+					return err
+				}
+				if err := redirectRepo.DeleteBySlug(models.RedirectTypePage, page.Slug); err != nil {
+					return err
+				}
+				report.PagesCreated++
+			}
+
+			for _, asset := range item.assets {
+				err := assetRepo.Create(&models.PageAsset{
+					PageID:    page.ID,
+					BucketKey: asset.BucketKey,
+					MimeType:  asset.MimeType,
+				})
+				if err != nil {
+					return err
+				}
+				report.AssetsImported++
+			}
+
+			if err := linkRepo.ReplaceForPage(page.ID, extractPageLinks(page)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to import website: %w", err)
+	}
+
+	for _, key := range replacedBlobs {
+		if err := s.store.Delete(key); err != nil {
+			log.Printf("Failed to remove replaced blob %s: %v", key, err)
+		}
+	}
+
+	return report, nil
+}
+
+// readImportPages decodes the page files of an archive and checks that the
+// assets they reference are present.
+func readImportPages(entries map[string][]byte, report *models.ImportReport) ([]*importPage, error) {
+	var names []string
+	for name := range entries {
+		if strings.HasPrefix(name, "pages/") && strings.HasSuffix(name, ".md") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var pages []*importPage
+	seen := make(map[string]string)
+	for _, name := range names {
+		front, content, err := decodePageFile(entries[name])
+		if err != nil {
+			return nil, invalidRequest(fmt.Sprintf("%s: %v", name, err))
+		}
+		if front.Title == "" || front.Slug == "" {
+			return nil, invalidRequest(name + ": title and slug are required")
+		}
+		if other, ok := seen[front.Slug]; ok {
+			return nil, invalidRequest(fmt.Sprintf("%s: slug %s is also used by %s", name, front.Slug, other))
+		}
+		seen[front.Slug] = name
+
+		switch front.Status {
+		case "":
+			front.Status = "draft"
+		case "draft", "translating", "translated", "ignored", "published":
+		default:
+			return nil, invalidRequest(fmt.Sprintf("%s: invalid status %s", name, front.Status))
+		}
+
+		page := &importPage{front: front, content: content}
+		for _, asset := range front.Assets {
+			if _, ok := entries[asset.File]; !ok {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: asset %s is missing from the archive", name, asset.File))
+				continue
+			}
+			if asset.MimeType == "" {
+				asset.MimeType = mime.TypeByExtension(path.Ext(asset.File))
+			}
+			if asset.MimeType == "" {
+				asset.MimeType = "application/octet-stream"
+			}
+			page.assets = append(page.assets, asset)
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// encodePageFile writes the page metadata as YAML front matter followed by
+// the page Markdown.
+func encodePageFile(front *models.ArchivePage, content string) ([]byte, error) {
+	meta, err := yaml.Marshal(front)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode front matter: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(meta)
+	buf.WriteString("---\n")
+	buf.WriteString(content)
+	return buf.Bytes(), nil
+}
+
+// decodePageFile splits a page file into its front matter and Markdown. Only
+// the first front matter block is consumed, so page content that has front
+// matter of its own survives a round trip.
+func decodePageFile(data []byte) (*models.ArchivePage, string, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, "", fmt.Errorf("missing front matter")
+	}
+	rest := "\n" + text[len("---\n"):]
+
+	var meta, content string
+	if end := strings.Index(rest, "\n---\n"); end >= 0 {
+		meta = rest[:end]
+		content = rest[end+len("\n---\n"):]
+	} else if strings.HasSuffix(rest, "\n---") {
+		meta = strings.TrimSuffix(rest, "\n---")
+	} else {
+		return nil, "", fmt.Errorf("unterminated front matter")
+	}
+
+	front := &models.ArchivePage{}
+	if err := yaml.Unmarshal([]byte(meta), front); err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+	return front, content, nil
+}
+
+// uniqueName appends the first free counter to name using suffix, a format
+// such as "-%d".
+func uniqueName(name, suffix string, taken func(string) bool) string {
+	for n := 2; ; n++ {
+		candidate := name + fmt.Sprintf(suffix, n)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+func importBatchID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate import ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+// Error codes identify the kind of failure in error responses. Clients may
+// match on them, so they must not change.
+const (
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeValidationFailed   = "validation_failed"
+	CodeForbidden          = "forbidden"
+	CodeSlugTaken          = "slug_taken"
+	CodeEmailTaken         = "email_taken"
+	CodeInvitationExists   = "invitation_exists"
+	CodeTagExists          = "tag_exists"
+	CodeRedirectExists     = "redirect_exists"
+	CodeLintFailed         = "lint_failed"
+	CodeImportConflict     = "import_conflict"
+	CodeBulkFailed         = "bulk_failed"
+	CodeMoved              = "moved"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodePreconditionFailed = "precondition_failed"
+	CodeEditConflict       = "edit_conflict"
+
+	CodePasswordChangeRequired = "password_change_required"
+	CodeLoginThrottled         = "login_throttled"
+	CodeAccountLocked          = "account_locked"
+	CodeInsufficientScope      = "insufficient_scope"
+	CodeSSOFailed              = "sso_failed"
+	CodeTwoFactorRequired      = "two_factor_required"
+	CodeInvalidTwoFactorCode   = "invalid_two_factor_code"
+	CodeLoginChallengeExpired  = "login_challenge_expired"
+	CodeMaintenance            = "maintenance"
+	CodeConfirmationMismatch   = "confirmation_mismatch"
+)
+
+// ErrNotFound matches, with errors.Is, every error reporting a missing
+// resource, whether it comes from a service or a repository.
+var ErrNotFound = repository.ErrNotFound
+
+// ErrVersionConflict matches, with errors.Is, a write that lost a race with
+// another write to the same resource.
+var ErrVersionConflict = repository.ErrVersionConflict
+
+// ErrInvalidCredentials is returned by Login for an unknown email or a
+// wrong password.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrInvalidTwoFactorCode is returned by CompleteTwoFactorLogin for a wrong
+// or already used code.
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// ErrLoginChallengeExpired is returned by CompleteTwoFactorLogin for an
+// unknown, expired or used-up login challenge.
+var ErrLoginChallengeExpired = errors.New("login challenge is invalid or has expired; log in again")
+
+// NotFoundError reports that a resource does not exist.
+type NotFoundError struct {
+	Resource string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Resource + " not found"
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError reports that a request clashes with existing state, such as
+// a slug that is already taken.
+type ConflictError struct {
+	Code    string
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// ValidationError reports invalid input together with the offending fields.
+type ValidationError struct {
+	Message string
+	Fields  []models.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// PreconditionFailedError reports that a conditional write (If-Match)
+// expected a version of the resource other than the current one.
+type PreconditionFailedError struct {
+	Resource       string
+	CurrentVersion int
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("%s has been modified; current version is %d", e.Resource, e.CurrentVersion)
+}
+
+// checkVersion verifies the version a conditional write expects; an
+// expected version of 0 means the write is unconditional.
+func checkVersion(resource string, current, expected int) error {
+	if expected != 0 && expected != current {
+		return &PreconditionFailedError{Resource: resource, CurrentVersion: current}
+	}
+	return nil
+}
+
+// ThrottledError reports that a login was refused without checking the
+// password because of recent failures.
+type ThrottledError struct {
+	RetryAfter time.Duration
+	// Locked is set when the account or client IP is locked out rather
+	// than backing off
+	Locked bool
+	// Message replaces the login wording for other throttled requests
+	Message string
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Locked {
+		return "too many failed logins; try again later"
+	}
+	return "too many failed logins; slow down"
+}
+
+// SSOError reports a single sign-on login that the identity provider
+// refused or that could not be completed, e.g. an invalid ID token.
+type SSOError struct {
+	Message string
+}
+
+func (e *SSOError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError reports that the caller may not perform an action.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// invalidField returns a ValidationError for a single field.
+func invalidField(field, code, message string) *ValidationError {
+	return &ValidationError{
+		Message: field + " " + message,
+		Fields:  []models.FieldError{{Field: field, Code: code, Message: message}},
+	}
+}
+
+// referenceError turns the not-found error of a resource referenced by a
+// request field into a ValidationError on that field.
+func referenceError(field string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return invalidField(field, CodeNotFound, "does not exist")
+	}
+	return err
+}
+
+// invalidRequest returns a ValidationError that is not tied to one field.
+func invalidRequest(message string) *ValidationError {
+	return &ValidationError{Message: message}
+}
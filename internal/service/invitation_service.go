@@ -0,0 +1,250 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/mailer"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+// InvitationOptions configures the InvitationService.
+type InvitationOptions struct {
+	// URL is the page of the frontend that accepts an invitation; the token
+	// is added as the "token" query parameter
+	URL string
+	TTL time.Duration
+}
+
+// InvitationService lets admins invite people by email. Invitees choose
+// their own name and password when they accept and get the invited role.
+type InvitationService struct {
+	invitationRepo *repository.InvitationRepository
+	userRepo       *repository.UserRepository
+	txManager      *repository.TxManager
+	userService    *UserService
+	mailer         mailer.Mailer
+	options        InvitationOptions
+}
+
+func NewInvitationService(invitationRepo *repository.InvitationRepository, userRepo *repository.UserRepository,
+	txManager *repository.TxManager, userService *UserService, mailer mailer.Mailer, options InvitationOptions) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		txManager:      txManager,
+		userService:    userService,
+		mailer:         mailer,
+		options:        options,
+	}
+}
+
+// GetInvitations lists the pending invitations, expired ones included.
+func (s *InvitationService) GetInvitations() ([]*models.Invitation, error) {
+	return s.invitationRepo.GetAll()
+}
+
+// Invite mails an invitation with a single-use token to an email address
+// on behalf of an admin. Addresses of existing users and addresses with a
+// pending invitation are refused; resend the invitation instead.
+func (s *InvitationService) Invite(adminID int, req *models.CreateInvitationRequest) (*models.Invitation, error) {
+	if err := s.userService.checkPasswordLogin(); err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	role, err := s.userRepo.GetRoleByID(req.RoleID)
+	if err != nil {
+		return nil, referenceError("roleId", err)
+	}
+
+	if _, err := s.userRepo.GetByEmail(email); err == nil {
+		return nil, &ConflictError{Code: CodeEmailTaken, Message: fmt.Sprintf("user with email %s already exists", email)}
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if _, err := s.invitationRepo.GetByEmail(email); err == nil {
+		return nil, &ConflictError{Code: CodeInvitationExists,
+			Message: fmt.Sprintf("%s has already been invited; resend the invitation instead", email)}
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+	invitation := &models.Invitation{
+		Email:     email,
+		RoleID:    role.ID,
+		Role:      role.Name,
+		InvitedBy: &adminID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.options.TTL),
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.invitationRepo.WithTx(tx).Create(invitation); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), adminID, models.ActivityInvitationSent,
+			map[string]interface{}{"invitationId": invitation.ID, "email": email, "role": role.Name})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.send(invitation, token); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// Resend mails a pending invitation again with a new token and a new
+// expiry. The previous link stops working.
+func (s *InvitationService) Resend(adminID, id int) (*models.Invitation, error) {
+	invitation, err := s.invitationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.invitationRepo.WithTx(tx).Renew(invitation, hashToken(token), time.Now().Add(s.options.TTL)); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), adminID, models.ActivityInvitationResent,
+			map[string]interface{}{"invitationId": invitation.ID, "email": invitation.Email})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.send(invitation, token); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// Revoke deletes a pending invitation, so its link stops working.
+func (s *InvitationService) Revoke(adminID, id int) error {
+	invitation, err := s.invitationRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.invitationRepo.WithTx(tx).Delete(invitation.ID); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), adminID, models.ActivityInvitationRevoked,
+			map[string]interface{}{"invitationId": invitation.ID, "email": invitation.Email})
+	})
+}
+
+// Accept creates the account of an invitee with the invited email and role
+// and the chosen name and password, and logs the new user in. The
+// invitation is consumed.
+func (s *InvitationService) Accept(req *models.AcceptInvitationRequest, client ClientInfo) (*models.LoginResponse, error) {
+	if err := s.userService.checkPasswordLogin(); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, invalidField("name", "required", "cannot be empty")
+	}
+
+	if err := s.userService.checkPasswordPolicy("password", req.Password); err != nil {
+		return nil, err
+	}
+
+	invalidToken := invalidField("token", "invalid", "is invalid or has expired")
+
+	invitation, err := s.invitationRepo.GetByTokenHash(hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, invalidToken
+		}
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user := &models.User{
+		Email:        invitation.Email,
+		PasswordHash: string(hashedPassword),
+		Name:         name,
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		// Deleting first makes a concurrent accept of the same invitation fail
+		if err := s.invitationRepo.WithTx(tx).Delete(invitation.ID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return invalidToken
+			}
+			return err
+		}
+
+		// The email may have been registered since the invitation was sent
+		if _, err := userRepo.GetByEmail(user.Email); err == nil {
+			return &ConflictError{Code: CodeEmailTaken, Message: fmt.Sprintf("user with email %s already exists", user.Email)}
+		} else if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		if err := userRepo.Create(user); err != nil {
+			return err
+		}
+		if err := userRepo.AddRole(user.ID, invitation.RoleID); err != nil {
+			return err
+		}
+		return logActivity(userRepo, user.ID, models.ActivityInvitationAccepted,
+			map[string]interface{}{"invitationId": invitation.ID, "role": invitation.Role, "invitedBy": invitation.InvitedBy})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userService.createSession(user, client)
+}
+
+// send mails the link of an invitation with its token.
+func (s *InvitationService) send(invitation *models.Invitation, token string) error {
+	link, err := url.Parse(s.options.URL)
+	if err != nil {
+		return fmt.Errorf("invalid invite URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	err = s.mailer.Send(&mailer.Message{
+		To:      invitation.Email,
+		Subject: "You are invited to XeoDocs",
+		Body: fmt.Sprintf("Hello,\n\n"+
+			"You have been invited to join XeoDocs as %s. Open this link to choose your name and password:\n\n"+
+			"%s\n\n"+
+			"The link works once and expires on %s. If you did not expect this invitation, you can ignore this email.\n",
+			invitation.Role, link.String(), invitation.ExpiresAt.UTC().Format("2006-01-02 15:04 MST")),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send invitation email: %w", err)
+	}
+	return nil
+}
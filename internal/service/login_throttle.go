@@ -0,0 +1,227 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+const (
+	// freeLoginFailures is the number of failures allowed before backoff
+	freeLoginFailures = 3
+
+	// blockCacheTTL bounds how long a replica trusts its cached block, so an
+	// unlock on another replica takes effect within this time
+	blockCacheTTL = 30 * time.Second
+
+	// throttlePruneInterval is how often expired counters are deleted
+	throttlePruneInterval = time.Hour
+)
+
+// LoginThrottleOptions configures brute-force protection of logins.
+type LoginThrottleOptions struct {
+	// MaxFailures locks an account after that many failures in a row
+	MaxFailures int
+	// IPMaxFailures locks a client IP after that many failures in a row
+	IPMaxFailures   int
+	LockoutDuration time.Duration
+	// Backoff doubles from BackoffBase up to BackoffMax for each failure
+	// after the first few
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// LoginThrottle tracks failed logins per account and per client IP. The
+// counters live in the database so all replicas share them; blocks are also
+// cached in memory, which rejects throttled attempts without a query.
+type LoginThrottle struct {
+	throttleRepo *repository.LoginThrottleRepository
+	txManager    *repository.TxManager
+	options      LoginThrottleOptions
+
+	mu        sync.Mutex
+	blocked   map[string]cachedBlock
+	lastPrune time.Time
+}
+
+type cachedBlock struct {
+	retryAt    time.Time
+	locked     bool
+	cacheUntil time.Time
+}
+
+func NewLoginThrottle(throttleRepo *repository.LoginThrottleRepository, txManager *repository.TxManager,
+	options LoginThrottleOptions) *LoginThrottle {
+	return &LoginThrottle{
+		throttleRepo: throttleRepo,
+		txManager:    txManager,
+		options:      options,
+		blocked:      make(map[string]cachedBlock),
+	}
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// resetThrottleKeys returns the keys counting password reset requests for
+// an email and a client IP, apart from failed logins.
+func resetThrottleKeys(email, ip string) (string, string) {
+	return "reset:" + accountThrottleKey(email), "reset:" + ipThrottleKey(ip)
+}
+
+// Check returns a *ThrottledError if any of the keys may not attempt a
+// login now.
+func (t *LoginThrottle) Check(keys ...string) error {
+	now := time.Now()
+	for _, key := range keys {
+		// Fast path: a cached block spares the database and bcrypt
+		if block, ok := t.cachedBlock(key, now); ok {
+			return &ThrottledError{RetryAfter: block.retryAt.Sub(now), Locked: block.locked}
+		}
+
+		throttle, err := t.throttleRepo.Get(key)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if retryAt, locked := blockedUntil(throttle, now); retryAt.After(now) {
+			t.cacheBlock(key, retryAt, locked, now)
+			return &ThrottledError{RetryAfter: retryAt.Sub(now), Locked: locked}
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login for key and blocks it with
+// exponential backoff, locking it once maxFailures is reached. It returns
+// the updated counter and whether this failure locked the key.
+func (t *LoginThrottle) RecordFailure(key string, maxFailures int) (*models.LoginThrottle, bool, error) {
+	now := time.Now()
+	t.prune(now)
+
+	var throttle *models.LoginThrottle
+	locked := false
+	err := t.txManager.WithTx(func(tx *sql.Tx) error {
+		throttleRepo := t.throttleRepo.WithTx(tx)
+
+		if err := throttleRepo.IncrementFailures(key, now.Add(-t.options.LockoutDuration)); err != nil {
+			return err
+		}
+		var err error
+		throttle, err = throttleRepo.Get(key)
+		if err != nil {
+			return err
+		}
+
+		throttle.NextAttemptAt, throttle.LockedUntil = nil, nil
+		if throttle.Failures >= maxFailures {
+			lockedUntil := now.Add(t.options.LockoutDuration)
+			throttle.LockedUntil = &lockedUntil
+			locked = true
+		} else if throttle.Failures >= freeLoginFailures {
+			nextAttemptAt := now.Add(t.backoff(throttle.Failures))
+			throttle.NextAttemptAt = &nextAttemptAt
+		}
+		return throttleRepo.SetBlock(key, throttle.NextAttemptAt, throttle.LockedUntil)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if retryAt, isLocked := blockedUntil(throttle, now); retryAt.After(now) {
+		t.cacheBlock(key, retryAt, isLocked, now)
+	}
+	return throttle, locked, nil
+}
+
+// Reset clears the failures and any lock of key.
+func (t *LoginThrottle) Reset(key string) error {
+	t.mu.Lock()
+	delete(t.blocked, key)
+	t.mu.Unlock()
+
+	return t.throttleRepo.Delete(key)
+}
+
+// backoff returns the delay before the next attempt after failures.
+func (t *LoginThrottle) backoff(failures int) time.Duration {
+	delay := t.options.BackoffBase
+	for i := freeLoginFailures; i < failures && delay < t.options.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > t.options.BackoffMax {
+		delay = t.options.BackoffMax
+	}
+	return delay
+}
+
+// blockedUntil returns when a counter allows the next attempt and whether
+// it is locked rather than backing off.
+func blockedUntil(throttle *models.LoginThrottle, now time.Time) (time.Time, bool) {
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+		return *throttle.LockedUntil, true
+	}
+	if throttle.NextAttemptAt != nil {
+		return *throttle.NextAttemptAt, false
+	}
+	return time.Time{}, false
+}
+
+func (t *LoginThrottle) cachedBlock(key string, now time.Time) (cachedBlock, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	block, ok := t.blocked[key]
+	if !ok {
+		return cachedBlock{}, false
+	}
+	if !now.Before(block.cacheUntil) || !now.Before(block.retryAt) {
+		delete(t.blocked, key)
+		return cachedBlock{}, false
+	}
+	return block, true
+}
+
+func (t *LoginThrottle) cacheBlock(key string, retryAt time.Time, locked bool, now time.Time) {
+	cacheUntil := now.Add(blockCacheTTL)
+	if retryAt.Before(cacheUntil) {
+		cacheUntil = retryAt
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.blocked[key] = cachedBlock{retryAt: retryAt, locked: locked, cacheUntil: cacheUntil}
+}
+
+// prune deletes expired counters and cached blocks, at most once per
+// throttlePruneInterval.
+func (t *LoginThrottle) prune(now time.Time) {
+	t.mu.Lock()
+	if now.Sub(t.lastPrune) < throttlePruneInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastPrune = now
+	for key, block := range t.blocked {
+		if !now.Before(block.cacheUntil) {
+			delete(t.blocked, key)
+		}
+	}
+	t.mu.Unlock()
+
+	// Counters expire after the lockout duration, see IncrementFailures;
+	// a failed cleanup is retried on the next interval
+	_ = t.throttleRepo.DeleteStale(now.Add(-t.options.LockoutDuration - t.options.BackoffMax))
+}
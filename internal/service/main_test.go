@@ -0,0 +1,116 @@
+package service
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/xeodocs/xeodocs-dash-api/internal/mailer"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+// seededAdminID is the admin account created by the base data migration.
+const seededAdminID = 1
+
+// newTestDB returns a database in a temporary directory with every
+// migration applied.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(migration)); err != nil {
+			t.Fatalf("migration %s: %v", filepath.Base(file), err)
+		}
+	}
+	return db
+}
+
+// recordingMailer keeps sent messages for inspection.
+type recordingMailer struct {
+	sent []*mailer.Message
+}
+
+func (m *recordingMailer) Send(msg *mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// newTestUserService returns a UserService on a fresh database with
+// default settings and a throttle that does not slow tests down.
+func newTestUserService(t *testing.T, db *sql.DB, mail mailer.Mailer) *UserService {
+	t.Helper()
+	userRepo := repository.NewUserRepository(db)
+	txManager := repository.NewTxManager(db)
+	throttle := NewLoginThrottle(repository.NewLoginThrottleRepository(db), txManager, LoginThrottleOptions{
+		MaxFailures:     10,
+		IPMaxFailures:   50,
+		LockoutDuration: 15 * time.Minute,
+		BackoffBase:     time.Millisecond,
+		BackoffMax:      time.Millisecond,
+	})
+	settings, err := NewSettingsService(repository.NewSystemConfigRepository(db), userRepo, txManager, noEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twoFactor := NewTwoFactorService(repository.NewTwoFactorRepository(db), userRepo, txManager, "Test")
+	return NewUserService(userRepo, repository.NewAPITokenRepository(db), txManager, throttle, twoFactor, settings, mail, AuthOptions{
+		PasswordResetURL: "http://localhost/reset",
+		PasswordResetTTL: time.Hour,
+	})
+}
+
+// noEnv is a lookupEnv without environment variables.
+func noEnv(string) (string, bool) {
+	return "", false
+}
+
+// createTestUser creates a user without roles.
+func createTestUser(t *testing.T, s *UserService, email string) *models.User {
+	t.Helper()
+	user, err := s.CreateUser(&models.CreateUserRequest{Email: email, Name: email, Password: "password123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// newTestPageService returns a PageService on db with default settings.
+func newTestPageService(t *testing.T, db *sql.DB) *PageService {
+	t.Helper()
+	txManager := repository.NewTxManager(db)
+	settings, err := NewSettingsService(repository.NewSystemConfigRepository(db), repository.NewUserRepository(db), txManager, noEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewPageService(repository.NewPageRepository(db), repository.NewWebsiteRepository(db),
+		repository.NewLinkRepository(db), repository.NewRedirectRepository(db), txManager, settings)
+}
+
+// createTestWebsite creates a website with the default configuration.
+func createTestWebsite(t *testing.T, db *sql.DB, slug string) *models.Website {
+	t.Helper()
+	website := &models.Website{Name: slug, Slug: slug, Domain: slug + ".example.com", LanguageCode: "en"}
+	if err := repository.NewWebsiteRepository(db).Create(website); err != nil {
+		t.Fatal(err)
+	}
+	return website
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/oidc"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+// OIDCLoginTTL bounds how long a user may take at the identity provider
+const OIDCLoginTTL = 10 * time.Minute
+
+// OIDCOptions configures single sign-on beyond the provider itself.
+type OIDCOptions struct {
+	// GroupsClaim names the ID token claim listing the user's groups
+	GroupsClaim string
+	// RoleMapping maps identity provider groups to role names. Roles it
+	// mentions are granted or removed on every login to match the groups;
+	// other roles are left alone.
+	RoleMapping map[string]string
+}
+
+// OIDCService logs users in through an OpenID Connect identity provider,
+// creating accounts on first login (just-in-time provisioning).
+type OIDCService struct {
+	provider      *oidc.Provider
+	oidcRepo      *repository.OIDCRepository
+	userRepo      *repository.UserRepository
+	twoFactorRepo *repository.TwoFactorRepository
+	txManager     *repository.TxManager
+	userService   *UserService
+	options       OIDCOptions
+}
+
+func NewOIDCService(provider *oidc.Provider, oidcRepo *repository.OIDCRepository, userRepo *repository.UserRepository,
+	twoFactorRepo *repository.TwoFactorRepository, txManager *repository.TxManager, userService *UserService,
+	options OIDCOptions) *OIDCService {
+	return &OIDCService{
+		provider:      provider,
+		oidcRepo:      oidcRepo,
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		txManager:     txManager,
+		userService:   userService,
+		options:       options,
+	}
+}
+
+// StartLogin begins a login: it stores a fresh state, nonce and PKCE code
+// verifier and returns the identity provider URL to send the user to,
+// along with the state the callback must present.
+func (s *OIDCService) StartLogin(ctx context.Context) (string, string, error) {
+	state, err := oidc.NewState()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Abandoned logins would otherwise pile up; failing to prune is harmless
+	_ = s.oidcRepo.DeleteExpiredLoginStates()
+
+	err = s.oidcRepo.CreateLoginState(&models.OIDCLoginState{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OIDCLoginTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// FinishLogin completes a login at the callback: it consumes the state,
+// redeems the code, validates the ID token and starts a session for the
+// linked, matched or newly provisioned user. Users with two-factor
+// authentication get a login challenge instead, as with passwords.
+func (s *OIDCService) FinishLogin(ctx context.Context, state, code string, client ClientInfo) (*models.LoginResponse, error) {
+	loginState, err := s.oidcRepo.GetLoginStateByHash(hashToken(state))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, &SSOError{Message: "login state is invalid or has expired; start the login again"}
+		}
+		return nil, err
+	}
+	if err := s.oidcRepo.DeleteLoginState(loginState.ID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			// Another request used the state first
+			return nil, &SSOError{Message: "login state has already been used; start the login again"}
+		}
+		return nil, err
+	}
+
+	claims, err := s.provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidToken) {
+			return nil, &SSOError{Message: err.Error()}
+		}
+		return nil, fmt.Errorf("failed to complete login with the identity provider: %w", err)
+	}
+
+	var user *models.User
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		var err error
+		user, err = s.provision(s.oidcRepo.WithTx(tx), s.userRepo.WithTx(tx), s.twoFactorRepo.WithTx(tx), claims)
+		if err != nil {
+			return err
+		}
+		return s.syncRoles(s.userRepo.WithTx(tx), user.ID, claims)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userService.beginSession(user, client)
+}
+
+// provision returns the user of an identity. Unknown identities are linked
+// to the user with the same, verified email or get a new user without a
+// password.
+func (s *OIDCService) provision(oidcRepo *repository.OIDCRepository, userRepo *repository.UserRepository,
+	twoFactorRepo *repository.TwoFactorRepository, claims *oidc.Claims) (*models.User, error) {
+	identity, err := oidcRepo.GetIdentity(claims.Issuer, claims.Subject)
+	if err == nil {
+		if err := oidcRepo.TouchIdentity(identity.ID); err != nil {
+			return nil, err
+		}
+		user, err := s.syncProfile(userRepo, identity.UserID, claims)
+		if errors.Is(err, ErrNotFound) {
+			// The linked user is in the trash
+			return nil, &SSOError{Message: "the account linked to this identity has been deleted"}
+		}
+		return user, err
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" {
+		return nil, &SSOError{Message: "the identity provider did not share an email address; request the email scope"}
+	}
+
+	user, err := userRepo.GetByEmail(email)
+	switch {
+	case err == nil:
+		// Only a verified email proves the identity owns the account
+		if !claims.EmailVerified {
+			return nil, &SSOError{Message: "an account with this email exists, but the identity provider has not verified the email"}
+		}
+		// A verified email at the provider is weaker than a password or a
+		// second factor, so only accounts without either are linked
+		protected, err := hasCredentials(twoFactorRepo, user)
+		if err != nil {
+			return nil, err
+		}
+		if protected {
+			return nil, &SSOError{Message: "an account with this email exists and has its own credentials; sign in with its password"}
+		}
+		if err := oidcRepo.CreateIdentity(&models.UserIdentity{UserID: user.ID, Issuer: claims.Issuer, Subject: claims.Subject}); err != nil {
+			return nil, err
+		}
+		err = logActivity(userRepo, user.ID, models.ActivityIdentityLinked,
+			map[string]interface{}{"issuer": claims.Issuer, "subject": claims.Subject})
+		return user, err
+	case !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = email
+	}
+	// An empty password hash never matches, so the user can only sign in
+	// through the identity provider
+	user = &models.User{Email: email, Name: name}
+	if err := userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	if err := oidcRepo.CreateIdentity(&models.UserIdentity{UserID: user.ID, Issuer: claims.Issuer, Subject: claims.Subject}); err != nil {
+		return nil, err
+	}
+	err = logActivity(userRepo, user.ID, models.ActivityUserProvisioned,
+		map[string]interface{}{"issuer": claims.Issuer, "subject": claims.Subject})
+	return user, err
+}
+
+// hasCredentials reports whether a user can sign in without the identity
+// provider, with a password or a second factor.
+func hasCredentials(twoFactorRepo *repository.TwoFactorRepository, user *models.User) (bool, error) {
+	if user.PasswordHash != "" {
+		return true, nil
+	}
+	enrollment, err := twoFactorRepo.GetTOTP(user.ID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return enrollment.EnabledAt != nil, nil
+}
+
+// syncProfile updates the name of a linked user from the ID token, so
+// renames at the identity provider carry over.
+func (s *OIDCService) syncProfile(userRepo *repository.UserRepository, userID int, claims *oidc.Claims) (*models.User, error) {
+	user, err := userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name != "" && name != user.Name {
+		user.Name = name
+		if err := userRepo.Update(user.ID, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// syncRoles grants and removes the mapped roles of a user to match the
+// groups in the ID token.
+func (s *OIDCService) syncRoles(userRepo *repository.UserRepository, userID int, claims *oidc.Claims) error {
+	if len(s.options.RoleMapping) == 0 {
+		return nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, group := range claims.StringsClaim(s.options.GroupsClaim) {
+		if role, ok := s.options.RoleMapping[group]; ok {
+			wanted[role] = true
+		}
+	}
+
+	current, err := userRepo.GetRoleNames(userID)
+	if err != nil {
+		return err
+	}
+	has := make(map[string]bool)
+	for _, name := range current {
+		has[name] = true
+	}
+
+	var granted, removed []string
+	seen := make(map[string]bool)
+	for _, name := range s.options.RoleMapping {
+		if seen[name] || wanted[name] == has[name] {
+			continue
+		}
+		seen[name] = true
+
+		role, err := userRepo.GetRoleByName(name)
+		if err != nil {
+			return fmt.Errorf("OIDC role mapping names role %q: %w", name, err)
+		}
+		if wanted[name] {
+			err = userRepo.AddRole(userID, role.ID)
+			granted = append(granted, name)
+		} else {
+			err = userRepo.RemoveRole(userID, role.ID)
+			removed = append(removed, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(granted) == 0 && len(removed) == 0 {
+		return nil
+	}
+	return logActivity(userRepo, userID, models.ActivityRolesSynced,
+		map[string]interface{}{"granted": granted, "removed": removed})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/oidc"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+func TestProvisionLinksOnlyAccountsWithoutCredentials(t *testing.T) {
+	db := newTestDB(t)
+	oidcRepo := repository.NewOIDCRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	s := &OIDCService{}
+
+	create := func(email, passwordHash string) *models.User {
+		user := &models.User{Email: email, Name: email, PasswordHash: passwordHash}
+		if err := userRepo.Create(user); err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	withPassword := create("password@example.com", "$2a$10$hash")
+	withTOTP := create("totp@example.com", "")
+	if err := twoFactorRepo.SavePendingTOTP(&models.UserTOTP{UserID: withTOTP.ID, Secret: "SECRET"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := twoFactorRepo.EnableTOTP(withTOTP.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	ssoOnly := create("sso@example.com", "")
+
+	tests := []struct {
+		name   string
+		user   *models.User
+		linked bool
+	}{
+		{name: "password", user: withPassword},
+		{name: "two-factor authentication", user: withTOTP},
+		{name: "no credentials", user: ssoOnly, linked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &oidc.Claims{Issuer: "https://idp.example.com", Subject: tt.user.Email,
+				Email: tt.user.Email, EmailVerified: true}
+			user, err := s.provision(oidcRepo, userRepo, twoFactorRepo, claims)
+			if !tt.linked {
+				var ssoErr *SSOError
+				if !errors.As(err, &ssoErr) {
+					t.Fatalf("provision() error = %v, want SSOError", err)
+				}
+				if _, err := oidcRepo.GetIdentity(claims.Issuer, claims.Subject); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("identity linked, GetIdentity() error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("provision() error = %v", err)
+			}
+			if user.ID != tt.user.ID {
+				t.Fatalf("provision() linked user %d, want %d", user.ID, tt.user.ID)
+			}
+		})
+	}
+}
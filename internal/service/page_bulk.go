@@ -0,0 +1,242 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+// maxBulkPages caps how many pages a single bulk request may touch.
+const maxBulkPages = 1000
+
+// BulkUpdatePages applies one operation to a set of pages selected by ID or
+// by filter. Every item is validated first; changes are then written in a
+// single transaction, and nothing is written if any item fails or the
+// request is a dry run.
+func (s *PageService) BulkUpdatePages(req *models.BulkPageRequest) (*models.BulkPageResponse, error) {
+	if err := validateBulkRequest(req); err != nil {
+		return nil, err
+	}
+
+	response := &models.BulkPageResponse{
+		Operation: req.Operation,
+		DryRun:    req.DryRun,
+		Results:   []models.BulkPageItemResult{},
+	}
+
+	pages, err := s.resolveBulkTargets(req, response)
+	if err != nil {
+		return nil, err
+	}
+	response.Matched = len(response.Results) + len(pages)
+	if response.Matched > maxBulkPages {
+		return nil, invalidRequest(fmt.Sprintf("bulk operation matches %d pages; at most %d are allowed per request", response.Matched, maxBulkPages))
+	}
+
+	// Compute and validate every change before writing anything
+	websites := make(map[int]*models.Website)
+	var changed []*models.Page
+	for _, page := range pages {
+		changes, err := s.applyBulkOperation(page, req, websites)
+		item := models.BulkPageItemResult{ID: page.ID, Slug: page.Slug, Changes: changes}
+		switch {
+		case err != nil:
+			item.Result = models.BulkResultFailed
+			item.Error = err.Error()
+		case len(changes) == 0:
+			item.Result = models.BulkResultUnchanged
+		case req.Operation == models.BulkOpDelete:
+			item.Result = models.BulkResultTrashed
+			changed = append(changed, page)
+		default:
+			item.Result = models.BulkResultUpdated
+			changed = append(changed, page)
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	for _, item := range response.Results {
+		if item.Result == models.BulkResultFailed {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+	if req.DryRun || response.Failed > 0 || len(changed) == 0 {
+		return response, nil
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		pageRepo := s.pageRepo.WithTx(tx)
+		linkRepo := s.linkRepo.WithTx(tx)
+		now := time.Now()
+
+		for _, page := range changed {
+			if req.Operation == models.BulkOpDelete {
+				// Like DeletePage: redirects stay for a restore
+				if err := linkRepo.DeleteBySourcePage(page.ID); err != nil {
+					return err
+				}
+				if err := pageRepo.Trash(page.ID, now); err != nil {
+					return fmt.Errorf("page %d: %w", page.ID, err)
+				}
+				continue
+			}
+			if err := pageRepo.Update(page.ID, page); err != nil {
+				return fmt.Errorf("page %d: %w", page.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response.Applied = true
+	return response, nil
+}
+
+func validateBulkRequest(req *models.BulkPageRequest) error {
+	if len(req.IDs) > 0 && req.Filter != nil {
+		return invalidRequest("specify either ids or filter, not both")
+	}
+	if len(req.IDs) == 0 && req.Filter == nil {
+		return invalidRequest("ids or filter is required")
+	}
+	if req.Filter != nil && *req.Filter == (models.PageFilter{}) {
+		return invalidField("filter", "required", "must set at least one criterion")
+	}
+
+	switch req.Operation {
+	case models.BulkOpSetStatus:
+		if req.Status == "" {
+			return invalidField("status", "required", "is required for "+req.Operation)
+		}
+	case models.BulkOpAddTags, models.BulkOpRemoveTags:
+		if len(req.Tags) == 0 {
+			return invalidField("tags", "required", "are required for "+req.Operation)
+		}
+	case models.BulkOpSchedulePublish:
+		if req.ScheduledPublishAt == nil {
+			return invalidField("scheduledPublishAt", "required", "is required for "+req.Operation)
+		}
+	}
+	return nil
+}
+
+// resolveBulkTargets loads the selected pages. IDs that do not exist are
+// recorded as failed results.
+func (s *PageService) resolveBulkTargets(req *models.BulkPageRequest, response *models.BulkPageResponse) ([]*models.Page, error) {
+	if req.Filter != nil {
+		return s.pageRepo.Find(req.Filter)
+	}
+
+	if len(req.IDs) > maxBulkPages {
+		return nil, invalidField("ids", "max", fmt.Sprintf("must have at most %d items", maxBulkPages))
+	}
+
+	var pages []*models.Page
+	seen := make(map[int]bool)
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		page, err := s.pageRepo.GetByID(id)
+		if err != nil {
+			response.Results = append(response.Results, models.BulkPageItemResult{
+				ID:     id,
+				Result: models.BulkResultFailed,
+				Error:  err.Error(),
+			})
+			continue
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// applyBulkOperation modifies page in memory and describes what changed.
+func (s *PageService) applyBulkOperation(page *models.Page, req *models.BulkPageRequest, websites map[int]*models.Website) ([]string, error) {
+	var changes []string
+
+	switch req.Operation {
+	case models.BulkOpSetStatus:
+		if page.Status == req.Status {
+			return nil, nil
+		}
+		if req.Status == "published" {
+			// Lint errors block the transition to published
+			website, ok := websites[page.WebsiteID]
+			if !ok {
+				var err error
+				website, err = s.websiteRepo.GetByID(page.WebsiteID)
+				if err != nil {
+					return nil, err
+				}
+				websites[page.WebsiteID] = website
+			}
+			if _, err := s.lintForSave(website, page.Slug, page.MarkdownContent, true); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, fmt.Sprintf("status: %s -> %s", page.Status, req.Status))
+		page.Status = req.Status
+		page.LastStatusChangeAt = time.Now()
+
+	case models.BulkOpFreeze, models.BulkOpUnfreeze:
+		freeze := req.Operation == models.BulkOpFreeze
+		if page.FreezeStatus == freeze {
+			return nil, nil
+		}
+		changes = append(changes, fmt.Sprintf("freezeStatus: %t -> %t", page.FreezeStatus, freeze))
+		page.FreezeStatus = freeze
+
+	case models.BulkOpAddTags, models.BulkOpRemoveTags:
+		present := make(map[string]bool)
+		for _, tag := range page.Tags {
+			present[tag] = true
+		}
+
+		tags := page.Tags
+		if req.Operation == models.BulkOpAddTags {
+			for _, tag := range normalizeTags(req.Tags) {
+				if !present[tag] {
+					present[tag] = true
+					tags = append(tags, tag)
+					changes = append(changes, "tag added: "+tag)
+				}
+			}
+		} else {
+			remove := make(map[string]bool)
+			for _, tag := range normalizeTags(req.Tags) {
+				remove[tag] = true
+			}
+			kept := []string{}
+			for _, tag := range tags {
+				if remove[tag] {
+					changes = append(changes, "tag removed: "+tag)
+					continue
+				}
+				kept = append(kept, tag)
+			}
+			tags = kept
+		}
+		page.Tags = tags
+
+	case models.BulkOpSchedulePublish:
+		if page.ScheduledPublishAt != nil && page.ScheduledPublishAt.Equal(*req.ScheduledPublishAt) {
+			return nil, nil
+		}
+		changes = append(changes, "scheduledPublishAt: "+req.ScheduledPublishAt.Format(time.RFC3339))
+		page.ScheduledPublishAt = req.ScheduledPublishAt
+
+	case models.BulkOpDelete:
+		changes = append(changes, "moved to the trash")
+	}
+
+	return changes, nil
+}
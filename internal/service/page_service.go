@@ -1,42 +1,78 @@
 package service
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/xeodocs/xeodocs-dash-api/internal/lint"
+	"github.com/xeodocs/xeodocs-dash-api/internal/markdown"
 	"github.com/xeodocs/xeodocs-dash-api/internal/models"
 	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/settings"
 )
 
 type PageService struct {
-	pageRepo    *repository.PageRepository
-	websiteRepo *repository.WebsiteRepository
+	pageRepo     *repository.PageRepository
+	websiteRepo  *repository.WebsiteRepository
+	linkRepo     *repository.LinkRepository
+	redirectRepo *repository.RedirectRepository
+	txManager    *repository.TxManager
+	settings     *SettingsService
+	linter       *lint.Linter
 }
 
-func NewPageService(pageRepo *repository.PageRepository, websiteRepo *repository.WebsiteRepository) *PageService {
-	return &PageService{
-		pageRepo:    pageRepo,
-		websiteRepo: websiteRepo,
+// LintError is returned when lint errors block a page from being published.
+type LintError struct {
+	Result *models.LintResult
+}
+
+func (e *LintError) Error() string {
+	return fmt.Sprintf("page has %d lint error(s) and cannot be published", e.Result.Errors)
+}
+
+func NewPageService(pageRepo *repository.PageRepository, websiteRepo *repository.WebsiteRepository,
+	linkRepo *repository.LinkRepository, redirectRepo *repository.RedirectRepository,
+	txManager *repository.TxManager, settings *SettingsService) *PageService {
+	s := &PageService{
+		pageRepo:     pageRepo,
+		websiteRepo:  websiteRepo,
+		linkRepo:     linkRepo,
+		redirectRepo: redirectRepo,
+		txManager:    txManager,
+		settings:     settings,
 	}
+	s.linter = lint.New(lint.DefaultRules(s.slugExists)...)
+	return s
 }
 
-func (s *PageService) CreatePage(req *models.CreatePageRequest) (*models.Page, error) {
+// RegisterLintRule adds a custom rule to the lint pass run on every save.
+func (s *PageService) RegisterLintRule(rule lint.Rule) {
+	s.linter.Register(rule)
+}
+
+func (s *PageService) CreatePage(req *models.CreatePageRequest) (*models.Page, *models.PageSaveResult, error) {
 	// Verify website exists
-	_, err := s.websiteRepo.GetByID(req.WebsiteID)
+	website, err := s.websiteRepo.GetByID(req.WebsiteID)
 	if err != nil {
-		return nil, fmt.Errorf("website not found: %w", err)
+		return nil, nil, referenceError("websiteId", err)
 	}
 
 	// Check if page with same slug already exists
 	existingPage, _ := s.pageRepo.GetBySlug(req.Slug)
 	if existingPage != nil {
-		return nil, fmt.Errorf("page with slug %s already exists", req.Slug)
+		return nil, nil, &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("page with slug %s already exists", req.Slug)}
+	}
+
+	status := req.Status
+	if status == "" {
+		status = s.defaultStatus(website)
 	}
 
-	// Set default tags if empty
-	tags := req.Tags
-	if tags == "" {
-		tags = "[]"
+	// Lint content; errors block pages created as published
+	lintResult, err := s.lintForSave(website, req.Slug, req.MarkdownContent, status == "published")
+	if err != nil {
+		return nil, &models.PageSaveResult{Lint: lintResult}, err
 	}
 
 	page := &models.Page{
@@ -45,26 +81,45 @@ func (s *PageService) CreatePage(req *models.CreatePageRequest) (*models.Page, e
 		Slug:               req.Slug,
 		Description:        req.Description,
 		MarkdownContent:    req.MarkdownContent,
-		Tags:               tags,
+		Tags:               normalizeTags(req.Tags),
 		FreezeStatus:       req.FreezeStatus,
-		Status:             req.Status,
+		Status:             status,
 		ScheduledPublishAt: req.ScheduledPublishAt,
 	}
 
-	err = s.pageRepo.Create(page)
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.pageRepo.WithTx(tx).Create(page); err != nil {
+			return err
+		}
+		// The slug is live now, so it can no longer redirect elsewhere
+		if err := s.redirectRepo.WithTx(tx).DeleteBySlug(models.RedirectTypePage, page.Slug); err != nil {
+			return err
+		}
+		return s.linkRepo.WithTx(tx).ReplaceForPage(page.ID, extractPageLinks(page))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create page: %w", err)
+		return nil, nil, fmt.Errorf("failed to create page: %w", err)
 	}
 
-	return page, nil
+	return page, &models.PageSaveResult{Lint: lintResult}, nil
 }
 
 func (s *PageService) GetPageByID(id int) (*models.Page, error) {
 	return s.pageRepo.GetByID(id)
 }
 
+// GetPageBySlug looks up a page by its current slug. Old slugs with a
+// redirect yield a *MovedError pointing to the page's new slug.
 func (s *PageService) GetPageBySlug(slug string) (*models.Page, error) {
-	return s.pageRepo.GetBySlug(slug)
+	page, err := s.pageRepo.GetBySlug(slug)
+	if err != nil {
+		redirect, redirectErr := s.redirectRepo.GetBySlug(models.RedirectTypePage, slug)
+		if redirectErr == nil && redirect.TargetSlug != "" {
+			return nil, &MovedError{Redirect: redirect}
+		}
+		return nil, err
+	}
+	return page, nil
 }
 
 func (s *PageService) GetAllPages() ([]*models.Page, error) {
@@ -75,31 +130,30 @@ func (s *PageService) GetPagesByWebsiteID(websiteID int) ([]*models.Page, error)
 	// Verify website exists
 	_, err := s.websiteRepo.GetByID(websiteID)
 	if err != nil {
-		return nil, fmt.Errorf("website not found: %w", err)
+		return nil, err
 	}
 
 	return s.pageRepo.GetByWebsiteID(websiteID)
 }
 
-func (s *PageService) UpdatePage(id int, req *models.UpdatePageRequest) (*models.Page, error) {
+// UpdatePage applies the provided fields to a page. A non-zero
+// expectedVersion makes the update conditional on the page's version.
+func (s *PageService) UpdatePage(id int, req *models.UpdatePageRequest, expectedVersion int) (*models.Page, *models.PageSaveResult, error) {
 	page, err := s.pageRepo.GetByID(id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if err := checkVersion("page", page.Version, expectedVersion); err != nil {
+		return nil, nil, err
 	}
 
-	// Track if status is changing for last_status_change_at
-	statusChanged := false
+	oldSlug, oldStatus := page.Slug, page.Status
 
 	// Update fields if provided
 	if req.Title != "" {
 		page.Title = req.Title
 	}
 	if req.Slug != "" {
-		// Check if new slug is already taken by another page
-		existingPage, _ := s.pageRepo.GetBySlug(req.Slug)
-		if existingPage != nil && existingPage.ID != id {
-			return nil, fmt.Errorf("slug %s is already taken", req.Slug)
-		}
 		page.Slug = req.Slug
 	}
 	if req.Description != "" {
@@ -108,33 +162,370 @@ func (s *PageService) UpdatePage(id int, req *models.UpdatePageRequest) (*models
 	if req.MarkdownContent != "" {
 		page.MarkdownContent = req.MarkdownContent
 	}
-	if req.Tags != "" {
-		page.Tags = req.Tags
+	if req.Tags != nil {
+		page.Tags = normalizeTags(req.Tags)
 	}
 	if req.FreezeStatus != nil {
 		page.FreezeStatus = *req.FreezeStatus
 	}
-	if req.Status != "" && req.Status != page.Status {
+	if req.Status != "" {
 		page.Status = req.Status
-		statusChanged = true
 	}
 	if req.ScheduledPublishAt != nil {
 		page.ScheduledPublishAt = req.ScheduledPublishAt
 	}
 
+	return s.saveUpdatedPage(page, oldSlug, oldStatus, req.RewriteLinks)
+}
+
+// PatchPage applies a JSON Merge Patch (RFC 7396) to a page. The patch
+// targets the page's fields as in CreatePageRequest; null clears a field.
+// The result is validated like a new page. A non-zero expectedVersion makes
+// the update conditional on the page's version.
+func (s *PageService) PatchPage(id int, patch []byte, rewriteLinks bool, expectedVersion int) (*models.Page, *models.PageSaveResult, error) {
+	page, err := s.pageRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkVersion("page", page.Version, expectedVersion); err != nil {
+		return nil, nil, err
+	}
+
+	doc := &models.CreatePageRequest{
+		WebsiteID:          page.WebsiteID,
+		Title:              page.Title,
+		Slug:               page.Slug,
+		Description:        page.Description,
+		MarkdownContent:    page.MarkdownContent,
+		Tags:               page.Tags,
+		FreezeStatus:       page.FreezeStatus,
+		Status:             page.Status,
+		ScheduledPublishAt: page.ScheduledPublishAt,
+	}
+	if err := applyMergePatch(doc, patch); err != nil {
+		return nil, nil, err
+	}
+	if doc.WebsiteID != page.WebsiteID {
+		return nil, nil, invalidField("websiteId", "readonly", "cannot be changed")
+	}
+
+	oldSlug, oldStatus := page.Slug, page.Status
+	page.Title = doc.Title
+	page.Slug = doc.Slug
+	page.Description = doc.Description
+	page.MarkdownContent = doc.MarkdownContent
+	page.Tags = normalizeTags(doc.Tags)
+	page.FreezeStatus = doc.FreezeStatus
+	page.Status = doc.Status
+	page.ScheduledPublishAt = doc.ScheduledPublishAt
+
+	return s.saveUpdatedPage(page, oldSlug, oldStatus, rewriteLinks)
+}
+
+// saveUpdatedPage lints and stores a modified page. When the slug changed,
+// it records a redirect from the old slug and lists, or rewrites, the pages
+// linking to it.
+func (s *PageService) saveUpdatedPage(page *models.Page, oldSlug, oldStatus string, rewriteLinks bool) (*models.Page, *models.PageSaveResult, error) {
+	if page.Slug != oldSlug {
+		// Check if new slug is already taken by another page
+		existingPage, _ := s.pageRepo.GetBySlug(page.Slug)
+		if existingPage != nil && existingPage.ID != page.ID {
+			return nil, nil, &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("slug %s is already taken", page.Slug)}
+		}
+	}
+	statusChanged := page.Status != oldStatus
+
 	// Update last_status_change_at if status changed
 	if statusChanged {
 		page.LastStatusChangeAt = time.Now()
 	}
 
-	err = s.pageRepo.Update(id, page)
+	// Find pages linking to the old slug, rewriting them if requested
+	var slugChange *models.SlugChange
+	var referringPages []*models.Page
+	if page.Slug != oldSlug {
+		var err error
+		slugChange, referringPages, err = s.planSlugChange(page, oldSlug, rewriteLinks)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Lint content; errors block any save that leaves the page published,
+	// so published pages cannot be edited into a broken state
+	website, err := s.websiteRepo.GetByID(page.WebsiteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	lintResult, err := s.lintForSave(website, page.Slug, page.MarkdownContent, page.Status == "published")
+	if err != nil {
+		return nil, &models.PageSaveResult{Lint: lintResult}, err
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		pageRepo := s.pageRepo.WithTx(tx)
+		linkRepo := s.linkRepo.WithTx(tx)
+
+		if err := pageRepo.Update(page.ID, page); err != nil {
+			return err
+		}
+		if err := linkRepo.ReplaceForPage(page.ID, extractPageLinks(page)); err != nil {
+			return err
+		}
+		if page.Slug != oldSlug {
+			err := recordSlugRename(s.redirectRepo.WithTx(tx), models.RedirectTypePage, page.WebsiteID, page.ID, oldSlug, page.Slug)
+			if err != nil {
+				return err
+			}
+		}
+		for _, referring := range referringPages {
+			if err := pageRepo.Update(referring.ID, referring); err != nil {
+				return err
+			}
+			if err := linkRepo.ReplaceForPage(referring.ID, extractPageLinks(referring)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return page, &models.PageSaveResult{Lint: lintResult, SlugChange: slugChange}, nil
+}
+
+// planSlugChange collects the pages linking to oldSlug. When rewrite is set,
+// links in those pages (and self-links in page) are pointed at the new slug,
+// and the modified referring pages are returned for saving.
+func (s *PageService) planSlugChange(page *models.Page, oldSlug string, rewrite bool) (*models.SlugChange, []*models.Page, error) {
+	inbound, err := s.linkRepo.GetInbound(page.WebsiteID, oldSlug)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	change := &models.SlugChange{
+		OldSlug:        oldSlug,
+		NewSlug:        page.Slug,
+		ReferringPages: []models.PageRef{},
+		LinksRewritten: rewrite,
+	}
+	refIndex := make(map[int]int)
+	for _, link := range inbound {
+		if link.SourcePageID == page.ID {
+			continue
+		}
+		if i, ok := refIndex[link.SourcePageID]; ok {
+			change.ReferringPages[i].Links++
+			continue
+		}
+		refIndex[link.SourcePageID] = len(change.ReferringPages)
+		change.ReferringPages = append(change.ReferringPages, models.PageRef{
+			ID:    link.SourcePageID,
+			Slug:  link.SourceSlug,
+			Title: link.SourceTitle,
+			Links: 1,
+		})
+	}
+
+	if !rewrite {
+		return change, nil, nil
+	}
+
+	page.MarkdownContent = rewriteSlugLinks(page.MarkdownContent, oldSlug, page.Slug)
+	var referringPages []*models.Page
+	for _, ref := range change.ReferringPages {
+		referring, err := s.pageRepo.GetByID(ref.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Referring pages are saved without the lint gate: the rewrite only
+		// retargets links to the renamed page, and unrelated errors in
+		// other pages must not block the rename
+		referring.MarkdownContent = rewriteSlugLinks(referring.MarkdownContent, oldSlug, page.Slug)
+		referringPages = append(referringPages, referring)
+	}
+	return change, referringPages, nil
+}
+
+// DeletePage moves a page to the trash and drops its links. A non-zero
+// expectedVersion makes the deletion conditional on the page's version.
+func (s *PageService) DeletePage(id, expectedVersion int) error {
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		page, err := s.pageRepo.WithTx(tx).GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("page", page.Version, expectedVersion); err != nil {
+			return err
+		}
+
+		// Outbound links are rebuilt on restore; redirects stay for it
+		if err := s.linkRepo.WithTx(tx).DeleteBySourcePage(id); err != nil {
+			return err
+		}
+		return s.pageRepo.WithTx(tx).Trash(id, time.Now())
+	})
+}
+
+// GetPageLinks returns the internal links from and to a page.
+func (s *PageService) GetPageLinks(id int) (*models.PageLinksResponse, error) {
+	page, err := s.pageRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	return page, nil
+	outbound, err := s.linkRepo.GetOutbound(page.ID)
+	if err != nil {
+		return nil, err
+	}
+	inbound, err := s.linkRepo.GetInbound(page.WebsiteID, page.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PageLinksResponse{Outbound: outbound, Inbound: inbound}, nil
+}
+
+// GetBrokenLinks lists every internal link in a website whose target slug has no page.
+func (s *PageService) GetBrokenLinks(websiteID int) (*models.BrokenLinkReport, error) {
+	// Verify website exists
+	_, err := s.websiteRepo.GetByID(websiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.linkRepo.CountByWebsite(websiteID)
+	if err != nil {
+		return nil, err
+	}
+	broken, err := s.linkRepo.GetBroken(websiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BrokenLinkReport{
+		WebsiteID:   websiteID,
+		TotalLinks:  total,
+		BrokenLinks: len(broken),
+		Links:       broken,
+	}, nil
 }
 
-func (s *PageService) DeletePage(id int) error {
-	return s.pageRepo.Delete(id)
+// RebuildLinks re-extracts the link table for every page of a website, e.g.
+// for pages saved before link tracking existed. It returns the number of pages indexed.
+func (s *PageService) RebuildLinks(websiteID int) (int, error) {
+	// Verify website exists
+	_, err := s.websiteRepo.GetByID(websiteID)
+	if err != nil {
+		return 0, err
+	}
+
+	pages, err := s.pageRepo.GetByWebsiteID(websiteID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		linkRepo := s.linkRepo.WithTx(tx)
+		for _, page := range pages {
+			if err := linkRepo.ReplaceForPage(page.ID, extractPageLinks(page)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(pages), nil
+}
+
+// LintPage runs the lint pass without saving anything.
+func (s *PageService) LintPage(req *models.LintPageRequest) (*models.LintResult, error) {
+	website, err := s.websiteRepo.GetByID(req.WebsiteID)
+	if err != nil {
+		return nil, referenceError("websiteId", err)
+	}
+
+	slug := req.Slug
+	if slug == "" && req.PageID != 0 {
+		page, err := s.pageRepo.GetByID(req.PageID)
+		if err != nil {
+			return nil, referenceError("pageId", err)
+		}
+		slug = page.Slug
+	}
+
+	return s.linter.Lint(website.ID, slug, req.MarkdownContent, websiteLintConfig(website)), nil
+}
+
+func (s *PageService) lintForSave(website *models.Website, slug, content string, publishing bool) (*models.LintResult, error) {
+	cfg := websiteLintConfig(website)
+	result := s.linter.Lint(website.ID, slug, content, cfg)
+
+	blockPublish := cfg == nil || cfg.BlockPublishOnError == nil || *cfg.BlockPublishOnError
+	if publishing && blockPublish && result.Errors > 0 {
+		return result, &LintError{Result: result}
+	}
+	return result, nil
+}
+
+func (s *PageService) slugExists(websiteID int, slug string) bool {
+	page, err := s.pageRepo.GetBySlug(slug)
+	return err == nil && page.WebsiteID == websiteID
+}
+
+// websiteLintConfig returns the "lint" section of the website configuration.
+func websiteLintConfig(website *models.Website) *models.LintConfig {
+	return website.Config.Lint
+}
+
+// defaultStatus returns the status of a new page created without one: the
+// workflow.defaultStatus of the website configuration, else the system
+// setting.
+func (s *PageService) defaultStatus(website *models.Website) string {
+	if workflow := website.Config.Workflow; workflow != nil && workflow.DefaultStatus != "" {
+		return workflow.DefaultStatus
+	}
+	return s.settings.String(settings.WorkflowDefaultStatus)
+}
+
+// extractPageLinks returns the internal page links found in a page's Markdown.
+func extractPageLinks(page *models.Page) []*models.PageLink {
+	var links []*models.PageLink
+	for _, link := range markdown.Parse(page.MarkdownContent).Links {
+		if link.Image {
+			continue
+		}
+		slug, ok := markdown.SlugFromTarget(link.Target)
+		if !ok {
+			continue
+		}
+		links = append(links, &models.PageLink{
+			WebsiteID:  page.WebsiteID,
+			TargetSlug: slug,
+			LinkText:   link.Text,
+			Line:       link.Line,
+			Column:     link.Column,
+		})
+	}
+	return links
+}
+
+// rewriteSlugLinks points the internal links to oldSlug in content at
+// newSlug. Only link targets change, so the result is not linted again.
+func rewriteSlugLinks(content, oldSlug, newSlug string) string {
+	content, _ = markdown.RewriteLinks(content, func(link markdown.Link) (string, bool) {
+		if link.Image {
+			return "", false
+		}
+		slug, ok := markdown.SlugFromTarget(link.Target)
+		if !ok || slug != oldSlug {
+			return "", false
+		}
+		return markdown.ReplaceTargetSlug(link.Target, oldSlug, newSlug), true
+	})
+	return content
 }
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+)
+
+func TestUpdatePublishedPageLintErrors(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestPageService(t, db)
+	website := createTestWebsite(t, db, "docs")
+
+	page, _, err := s.CreatePage(&models.CreatePageRequest{WebsiteID: website.ID, Title: "Guide", Slug: "guide",
+		MarkdownContent: "# Guide\n", Status: "published"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A published page stays published, so the edit must pass the lint too
+	broken := "# Guide\n\nSee [install](install).\n"
+	_, result, err := s.UpdatePage(page.ID, &models.UpdatePageRequest{MarkdownContent: broken}, 0)
+	var lintErr *LintError
+	if !errors.As(err, &lintErr) {
+		t.Fatalf("UpdatePage(published, dead link) error = %v, want LintError", err)
+	}
+	if result == nil || result.Lint == nil || result.Lint.Errors == 0 {
+		t.Fatalf("UpdatePage() result = %+v, want lint errors", result)
+	}
+	stored, _ := s.GetPageByID(page.ID)
+	if stored.MarkdownContent != page.MarkdownContent {
+		t.Fatal("content of the published page changed")
+	}
+
+	// Drafts may be saved with errors
+	if _, _, err := s.UpdatePage(page.ID, &models.UpdatePageRequest{MarkdownContent: broken, Status: "draft"}, 0); err != nil {
+		t.Fatalf("UpdatePage(draft, dead link) error = %v", err)
+	}
+}
+
+func TestRewriteLinksSkipsLintOfReferringPages(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestPageService(t, db)
+	website := createTestWebsite(t, db, "docs")
+
+	create := func(slug, content string) *models.Page {
+		page, _, err := s.CreatePage(&models.CreatePageRequest{WebsiteID: website.ID, Title: slug, Slug: slug,
+			MarkdownContent: content, Status: "published"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return page
+	}
+	guide := create("guide", "# Guide\n")
+	faq := create("faq", "# FAQ\n")
+	intro := create("intro", "# Intro\n\nRead the [guide](guide) and the [FAQ](faq).\n")
+
+	// Deleting the FAQ leaves a dead link, a lint error, in the published intro
+	if err := s.DeletePage(faq.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	_, result, err := s.UpdatePage(guide.ID, &models.UpdatePageRequest{Slug: "handbook", RewriteLinks: true}, 0)
+	if err != nil {
+		t.Fatalf("UpdatePage(rename) error = %v", err)
+	}
+	if result.SlugChange == nil || len(result.SlugChange.ReferringPages) != 1 {
+		t.Fatalf("slugChange = %+v, want the intro page", result.SlugChange)
+	}
+
+	stored, err := s.GetPageByID(intro.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Intro\n\nRead the [guide](handbook) and the [FAQ](faq).\n"
+	if stored.MarkdownContent != want {
+		t.Errorf("intro content = %q, want %q", stored.MarkdownContent, want)
+	}
+	if lint := s.linter.Lint(website.ID, stored.Slug, stored.MarkdownContent, nil); lint.Errors == 0 {
+		t.Error("intro has no lint errors; the test does not cover the exemption")
+	}
+}
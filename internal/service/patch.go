@@ -0,0 +1,90 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+// documentValidator checks patched documents with the same binding rules
+// the handlers apply to request bodies.
+var documentValidator = newDocumentValidator()
+
+func newDocumentValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(utils.JSONFieldName)
+	return v
+}
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7396) to doc, a request
+// struct holding the current state of a resource, and validates the result
+// with the struct's binding rules. Members set to null are removed, so they
+// decode to their zero value.
+func applyMergePatch(doc interface{}, patch []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil || members == nil {
+		return invalidRequest("patch must be a JSON object")
+	}
+
+	// Report every unknown member, not just the first one
+	docType := reflect.TypeOf(doc).Elem()
+	known := make(map[string]bool)
+	for i := 0; i < docType.NumField(); i++ {
+		if name := utils.JSONFieldName(docType.Field(i)); name != "" {
+			known[name] = true
+		}
+	}
+	var fieldErrors []models.FieldError
+	for name := range members {
+		if !known[name] {
+			fieldErrors = append(fieldErrors, models.FieldError{Field: name, Code: "unknown_field", Message: "unknown field"})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		sort.Slice(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+		return &ValidationError{Message: "patch contains unknown fields", Fields: fieldErrors}
+	}
+
+	current, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+	merged, err := utils.MergePatch(current, patch)
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+
+	// Decode into a zeroed document so removed members are cleared
+	value := reflect.ValueOf(doc).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	if err := json.NewDecoder(bytes.NewReader(merged)).Decode(doc); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return invalidField(utils.JSONPath(typeErr.Field), "type", "must be "+utils.JSONTypeName(typeErr.Type))
+		}
+		return invalidRequest(err.Error())
+	}
+
+	if err := documentValidator.Struct(doc); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return err
+		}
+		for _, e := range validationErrs {
+			// Drop the document type name from paths such as "CreatePageRequest.tags[0]"
+			_, field, _ := strings.Cut(e.Namespace(), ".")
+			fieldErrors = append(fieldErrors, models.FieldError{Field: field, Code: e.Tag(), Message: utils.ValidationMessage(e)})
+		}
+		return &ValidationError{Message: "patched document failed validation", Fields: fieldErrors}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/preferences"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+// PreferenceService manages the dashboard preferences of users.
+type PreferenceService struct {
+	userConfigRepo *repository.UserConfigRepository
+	websiteRepo    *repository.WebsiteRepository
+	txManager      *repository.TxManager
+}
+
+func NewPreferenceService(userConfigRepo *repository.UserConfigRepository, websiteRepo *repository.WebsiteRepository,
+	txManager *repository.TxManager) *PreferenceService {
+	return &PreferenceService{
+		userConfigRepo: userConfigRepo,
+		websiteRepo:    websiteRepo,
+		txManager:      txManager,
+	}
+}
+
+// GetPreferences returns the preferences of a user; unset keys are left
+// out.
+func (s *PreferenceService) GetPreferences(userID int) (*models.UserPreferences, error) {
+	values, err := s.userConfigRepo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return preferences.Decode(values), nil
+}
+
+// ReplacePreferences sets the preferences of a user to raw preferences
+// JSON; keys it leaves out are cleared.
+func (s *PreferenceService) ReplacePreferences(userID int, raw []byte) (*models.UserPreferences, error) {
+	return s.save(userID, raw)
+}
+
+// PatchPreferences applies a JSON Merge Patch (RFC 7396) to the
+// preferences of a user and validates the result like a replacement.
+func (s *PreferenceService) PatchPreferences(userID int, patch []byte) (*models.UserPreferences, error) {
+	prefs, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := json.Marshal(prefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	merged, err := utils.MergePatch(current, patch)
+	if err != nil {
+		return nil, invalidRequest(err.Error())
+	}
+	return s.save(userID, merged)
+}
+
+// save validates raw preferences JSON and stores it.
+func (s *PreferenceService) save(userID int, raw []byte) (*models.UserPreferences, error) {
+	prefs, fieldErrors := preferences.Parse(raw)
+	if len(fieldErrors) > 0 {
+		return nil, &ValidationError{Message: "invalid preferences", Fields: fieldErrors}
+	}
+	if prefs.DefaultWebsiteID != nil {
+		if _, err := s.websiteRepo.GetByID(*prefs.DefaultWebsiteID); err != nil {
+			return nil, referenceError("preferences.defaultWebsiteId", err)
+		}
+	}
+
+	values, err := preferences.Encode(prefs)
+	if err != nil {
+		return nil, err
+	}
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		return s.userConfigRepo.WithTx(tx).Replace(userID, values)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
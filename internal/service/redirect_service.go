@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+// MovedError is returned by slug lookups when the slug has been redirected
+// to another resource.
+type MovedError struct {
+	Redirect *models.Redirect
+}
+
+func (e *MovedError) Error() string {
+	return fmt.Sprintf("%s %s has moved to %s", e.Redirect.ResourceType, e.Redirect.OldSlug, e.Redirect.TargetSlug)
+}
+
+type RedirectService struct {
+	redirectRepo *repository.RedirectRepository
+	pageRepo     *repository.PageRepository
+	websiteRepo  *repository.WebsiteRepository
+}
+
+func NewRedirectService(redirectRepo *repository.RedirectRepository, pageRepo *repository.PageRepository,
+	websiteRepo *repository.WebsiteRepository) *RedirectService {
+	return &RedirectService{
+		redirectRepo: redirectRepo,
+		pageRepo:     pageRepo,
+		websiteRepo:  websiteRepo,
+	}
+}
+
+func (s *RedirectService) CreateRedirect(req *models.CreateRedirectRequest) (*models.Redirect, error) {
+	redirect := &models.Redirect{
+		ResourceType: req.ResourceType,
+		OldSlug:      req.OldSlug,
+		TargetID:     req.TargetID,
+		StatusCode:   req.StatusCode,
+		IsManual:     true,
+	}
+	if redirect.StatusCode == 0 {
+		redirect.StatusCode = 301
+	}
+
+	if err := s.validate(redirect, 0); err != nil {
+		return nil, err
+	}
+
+	err := s.redirectRepo.Create(redirect)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create redirect: %w", err)
+	}
+
+	// Reload to resolve the target's current slug
+	return s.redirectRepo.GetByID(redirect.ID)
+}
+
+func (s *RedirectService) GetRedirectByID(id int) (*models.Redirect, error) {
+	return s.redirectRepo.GetByID(id)
+}
+
+func (s *RedirectService) GetRedirects(websiteID int, resourceType string) ([]*models.Redirect, error) {
+	return s.redirectRepo.GetAll(websiteID, resourceType)
+}
+
+func (s *RedirectService) UpdateRedirect(id int, req *models.UpdateRedirectRequest) (*models.Redirect, error) {
+	redirect, err := s.redirectRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if req.OldSlug != "" {
+		redirect.OldSlug = req.OldSlug
+	}
+	if req.TargetID != 0 {
+		redirect.TargetID = req.TargetID
+	}
+	if req.StatusCode != 0 {
+		redirect.StatusCode = req.StatusCode
+	}
+
+	if err := s.validate(redirect, id); err != nil {
+		return nil, err
+	}
+
+	err = s.redirectRepo.Update(id, redirect)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.redirectRepo.GetByID(id)
+}
+
+func (s *RedirectService) DeleteRedirect(id int) error {
+	return s.redirectRepo.Delete(id)
+}
+
+// ExportRedirects renders the page redirects of a website for the static
+// site build. Supported formats are "json" and "netlify" (a _redirects file).
+func (s *RedirectService) ExportRedirects(websiteID int, format string) ([]byte, string, error) {
+	// Verify website exists
+	_, err := s.websiteRepo.GetByID(websiteID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	redirects, err := s.redirectRepo.GetAll(websiteID, models.RedirectTypePage)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case "", "json":
+		type entry struct {
+			From       string `json:"from"`
+			To         string `json:"to"`
+			StatusCode int    `json:"statusCode"`
+		}
+		entries := []entry{}
+		for _, redirect := range redirects {
+			entries = append(entries, entry{From: "/" + redirect.OldSlug, To: "/" + redirect.TargetSlug, StatusCode: redirect.StatusCode})
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode redirects: %w", err)
+		}
+		return data, "application/json", nil
+	case "netlify":
+		var buf bytes.Buffer
+		for _, redirect := range redirects {
+			fmt.Fprintf(&buf, "/%s /%s %d\n", redirect.OldSlug, redirect.TargetSlug, redirect.StatusCode)
+		}
+		return buf.Bytes(), "text/plain; charset=utf-8", nil
+	default:
+		return nil, "", invalidField("format", "oneof", "must be one of: json, netlify")
+	}
+}
+
+// validate checks the redirect target and derives the owning website. A
+// redirect may not shadow a slug that is currently in use.
+func (s *RedirectService) validate(redirect *models.Redirect, id int) error {
+	switch redirect.ResourceType {
+	case models.RedirectTypePage:
+		target, err := s.pageRepo.GetByID(redirect.TargetID)
+		if err != nil {
+			return referenceError("targetId", err)
+		}
+		if live, _ := s.pageRepo.GetBySlug(redirect.OldSlug); live != nil {
+			return &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("slug %s is in use by page %d", redirect.OldSlug, live.ID)}
+		}
+		redirect.WebsiteID = target.WebsiteID
+	case models.RedirectTypeWebsite:
+		target, err := s.websiteRepo.GetByID(redirect.TargetID)
+		if err != nil {
+			return referenceError("targetId", err)
+		}
+		if live, _ := s.websiteRepo.GetBySlug(redirect.OldSlug); live != nil {
+			return &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("slug %s is in use by website %d", redirect.OldSlug, live.ID)}
+		}
+		redirect.WebsiteID = target.ID
+	}
+
+	existing, _ := s.redirectRepo.GetBySlug(redirect.ResourceType, redirect.OldSlug)
+	if existing != nil && existing.ID != id {
+		return &ConflictError{Code: CodeRedirectExists, Message: fmt.Sprintf("a redirect for %s %s already exists", redirect.ResourceType, redirect.OldSlug)}
+	}
+	return nil
+}
+
+// recordSlugRename points oldSlug at the renamed resource and drops any
+// redirect claiming newSlug, which is live again.
+func recordSlugRename(redirectRepo *repository.RedirectRepository, resourceType string, websiteID, targetID int, oldSlug, newSlug string) error {
+	if err := redirectRepo.DeleteBySlug(resourceType, newSlug); err != nil {
+		return err
+	}
+	if err := redirectRepo.DeleteBySlug(resourceType, oldSlug); err != nil {
+		return err
+	}
+	return redirectRepo.Create(&models.Redirect{
+		ResourceType: resourceType,
+		WebsiteID:    websiteID,
+		OldSlug:      oldSlug,
+		TargetID:     targetID,
+		StatusCode:   301,
+	})
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/settings"
+)
+
+// settingsCacheTTL bounds how long a replica trusts its cached settings, so
+// a change made through another replica takes effect within this time
+const settingsCacheTTL = 30 * time.Second
+
+// SettingsService serves the runtime system settings. Environment
+// variables override stored values, which override the defaults (see
+// package settings). Stored values are cached in memory; a change through
+// this service refreshes the cache at once.
+type SettingsService struct {
+	configRepo *repository.SystemConfigRepository
+	userRepo   *repository.UserRepository
+	txManager  *repository.TxManager
+
+	// env holds the settings fixed by environment variables
+	env map[string]interface{}
+
+	mu         sync.Mutex
+	stored     map[string]interface{}
+	cacheUntil time.Time
+}
+
+// NewSettingsService reads the environment overrides of the settings with
+// lookupEnv, usually os.LookupEnv, and fails if one is invalid or if the
+// effective settings, with the stored ones, break a rule across settings.
+func NewSettingsService(configRepo *repository.SystemConfigRepository, userRepo *repository.UserRepository,
+	txManager *repository.TxManager, lookupEnv func(string) (string, bool)) (*SettingsService, error) {
+	env := make(map[string]interface{})
+	for _, def := range settings.Definitions {
+		raw, ok := lookupEnv(def.Env)
+		if !ok || raw == "" {
+			continue
+		}
+		value, err := def.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", def.Env, err)
+		}
+		env[def.Key] = value
+	}
+
+	s := &SettingsService{
+		configRepo: configRepo,
+		userRepo:   userRepo,
+		txManager:  txManager,
+		env:        env,
+		stored:     make(map[string]interface{}),
+	}
+	effective := make(map[string]interface{})
+	for _, def := range settings.Definitions {
+		effective[def.Key], _ = s.value(def.Key)
+	}
+	if fieldErrors := checkSettings(effective); len(fieldErrors) > 0 {
+		return nil, fmt.Errorf("%s %s", fieldErrors[0].Field, fieldErrors[0].Message)
+	}
+	return s, nil
+}
+
+// Duration returns the value of a duration setting.
+func (s *SettingsService) Duration(key string) time.Duration {
+	value, _ := s.value(key)
+	return value.(time.Duration)
+}
+
+// Int returns the value of an integer setting.
+func (s *SettingsService) Int(key string) int {
+	value, _ := s.value(key)
+	return value.(int)
+}
+
+// Bool returns the value of a boolean setting.
+func (s *SettingsService) Bool(key string) bool {
+	value, _ := s.value(key)
+	return value.(bool)
+}
+
+// String returns the value of a string or enum setting.
+func (s *SettingsService) String(key string) string {
+	value, _ := s.value(key)
+	return value.(string)
+}
+
+// Maintenance reports whether the API is in read-only maintenance mode.
+// Background jobs skip their runs while it is on.
+func (s *SettingsService) Maintenance() *models.MaintenanceStatus {
+	if !s.Bool(settings.MaintenanceEnabled) {
+		return &models.MaintenanceStatus{}
+	}
+	return &models.MaintenanceStatus{
+		Enabled:    true,
+		Message:    s.String(settings.MaintenanceMessage),
+		RetryAfter: s.Duration(settings.MaintenanceRetryAfter),
+	}
+}
+
+// GetSettings lists every setting with its current value and source.
+func (s *SettingsService) GetSettings() []*models.SystemSetting {
+	list := make([]*models.SystemSetting, 0, len(settings.Definitions))
+	for _, def := range settings.Definitions {
+		value, source := s.value(def.Key)
+		setting := &models.SystemSetting{
+			Key:         def.Key,
+			Type:        string(def.Type),
+			Value:       def.Encode(value),
+			Default:     def.Encode(def.Default),
+			Source:      source,
+			Env:         def.Env,
+			MaxLength:   def.MaxLength,
+			Options:     def.Options,
+			Description: def.Description,
+		}
+		if def.Min != nil {
+			setting.Minimum = def.Encode(def.Min)
+			setting.Maximum = def.Encode(def.Max)
+		}
+		list = append(list, setting)
+	}
+	return list
+}
+
+// UpdateSettings applies a JSON Merge Patch (RFC 7396) keyed by setting to
+// the stored settings on behalf of an admin: members set a value and null
+// removes it, so the default applies again. Settings fixed by the
+// environment cannot be changed. The change is recorded in the admin's log.
+func (s *SettingsService) UpdateSettings(adminID int, patch []byte) ([]*models.SystemSetting, error) {
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &changes); err != nil || changes == nil {
+		return nil, invalidRequest("settings patch must be a JSON object keyed by setting")
+	}
+
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Effective values after the change, for checks across settings
+	effective := make(map[string]interface{})
+	for _, def := range settings.Definitions {
+		effective[def.Key], _ = s.value(def.Key)
+	}
+
+	updates := make(map[string]interface{})
+	var fieldErrors []models.FieldError
+	for _, key := range keys {
+		def, ok := settings.Lookup(key)
+		if !ok {
+			fieldErrors = append(fieldErrors, models.FieldError{Field: key, Code: "unknown_field", Message: "unknown setting"})
+			continue
+		}
+		if _, fixed := s.env[key]; fixed {
+			fieldErrors = append(fieldErrors, models.FieldError{Field: key, Code: "env_override",
+				Message: "is set by the " + def.Env + " environment variable"})
+			continue
+		}
+
+		raw := bytes.TrimSpace(changes[key])
+		if bytes.Equal(raw, []byte("null")) {
+			updates[key] = nil
+			effective[key] = def.Default
+			continue
+		}
+		value, err := def.Decode(raw)
+		if err != nil {
+			invalid := err.(*settings.InvalidError)
+			fieldErrors = append(fieldErrors, models.FieldError{Field: key, Code: invalid.Code, Message: invalid.Message})
+			continue
+		}
+		updates[key] = value
+		effective[key] = value
+	}
+	if len(fieldErrors) == 0 {
+		fieldErrors = checkSettings(effective)
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &ValidationError{Message: "invalid settings", Fields: fieldErrors}
+	}
+
+	logged := make(map[string]interface{}, len(updates))
+	err := s.txManager.WithTx(func(tx *sql.Tx) error {
+		configRepo := s.configRepo.WithTx(tx)
+
+		for _, key := range keys {
+			def, _ := settings.Lookup(key)
+			value := updates[key]
+			if value == nil {
+				logged[key] = nil
+				if err := configRepo.Delete(key); err != nil {
+					return err
+				}
+				continue
+			}
+
+			encoded, err := json.Marshal(def.Encode(value))
+			if err != nil {
+				return fmt.Errorf("failed to encode setting %s: %w", key, err)
+			}
+			logged[key] = def.Encode(value)
+			if err := configRepo.Set(key, string(encoded)); err != nil {
+				return err
+			}
+		}
+		return logActivity(s.userRepo.WithTx(tx), adminID, models.ActivitySystemSettingsChanged,
+			map[string]interface{}{"changes": logged})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return s.GetSettings(), nil
+}
+
+// checkSettings checks the rules across settings on their effective
+// values, keyed by setting.
+func checkSettings(effective map[string]interface{}) []models.FieldError {
+	if effective[settings.SessionIdleTimeout].(time.Duration) > effective[settings.SessionMaxLifetime].(time.Duration) {
+		return []models.FieldError{{Field: settings.SessionIdleTimeout, Code: "max",
+			Message: "must not exceed " + settings.SessionMaxLifetime}}
+	}
+	return nil
+}
+
+// value returns the value of a setting and where it comes from.
+func (s *SettingsService) value(key string) (interface{}, string) {
+	def, ok := settings.Lookup(key)
+	if !ok {
+		panic("unknown setting " + key)
+	}
+	if value, ok := s.env[key]; ok {
+		return value, models.SettingSourceEnv
+	}
+	if value, ok := s.load()[key]; ok {
+		return value, models.SettingSourceDatabase
+	}
+	return def.Default, models.SettingSourceDefault
+}
+
+// load returns the stored settings, from the cache while it is fresh.
+// Stored values that are invalid, e.g. after a bound changed, are ignored.
+// If the database fails, the last known values are kept.
+func (s *SettingsService) load() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(s.cacheUntil) {
+		return s.stored
+	}
+
+	rows, err := s.configRepo.GetAll()
+	if err != nil {
+		log.Printf("Failed to load system settings: %v", err)
+		return s.stored
+	}
+
+	stored := make(map[string]interface{}, len(rows))
+	for key, raw := range rows {
+		def, ok := settings.Lookup(key)
+		if !ok {
+			continue
+		}
+		value, err := def.Decode(json.RawMessage(raw))
+		if err != nil {
+			log.Printf("Ignoring invalid system setting %s: %v", key, err)
+			continue
+		}
+		stored[key] = value
+	}
+	s.stored = stored
+	s.cacheUntil = now.Add(settingsCacheTTL)
+	return stored
+}
+
+// invalidate makes the next read load the stored settings again.
+func (s *SettingsService) invalidate() {
+	s.mu.Lock()
+	s.cacheUntil = time.Time{}
+	s.mu.Unlock()
+}
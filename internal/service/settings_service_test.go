@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/settings"
+)
+
+func TestNewSettingsServiceSessionTimeouts(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		stored map[string]string
+		valid  bool
+	}{
+		{name: "defaults", valid: true},
+		{name: "environment within lifetime", env: map[string]string{"SESSION_IDLE_TIMEOUT": "1h", "SESSION_MAX_LIFETIME": "2h"},
+			valid: true},
+		{name: "environment above lifetime", env: map[string]string{"SESSION_IDLE_TIMEOUT": "48h", "SESSION_MAX_LIFETIME": "24h"}},
+		{name: "environment above default lifetime", env: map[string]string{"SESSION_IDLE_TIMEOUT": "200h"}},
+		{name: "stored above environment lifetime", env: map[string]string{"SESSION_MAX_LIFETIME": "2h"},
+			stored: map[string]string{settings.SessionIdleTimeout: `"3h"`}},
+		{name: "environment above stored lifetime", env: map[string]string{"SESSION_IDLE_TIMEOUT": "3h"},
+			stored: map[string]string{settings.SessionMaxLifetime: `"2h"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			configRepo := repository.NewSystemConfigRepository(db)
+			for key, value := range tt.stored {
+				if err := configRepo.Set(key, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			lookupEnv := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+
+			_, err := NewSettingsService(configRepo, repository.NewUserRepository(db), repository.NewTxManager(db), lookupEnv)
+			if tt.valid && err != nil {
+				t.Fatalf("NewSettingsService() error = %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("NewSettingsService() accepted an idle timeout above the maximum lifetime")
+			}
+		})
+	}
+}
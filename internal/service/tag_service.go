@@ -0,0 +1,128 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+type TagService struct {
+	tagRepo     *repository.TagRepository
+	websiteRepo *repository.WebsiteRepository
+	txManager   *repository.TxManager
+}
+
+func NewTagService(tagRepo *repository.TagRepository, websiteRepo *repository.WebsiteRepository,
+	txManager *repository.TxManager) *TagService {
+	return &TagService{
+		tagRepo:     tagRepo,
+		websiteRepo: websiteRepo,
+		txManager:   txManager,
+	}
+}
+
+func (s *TagService) GetTags(websiteID int) ([]*models.Tag, error) {
+	// Verify website exists
+	_, err := s.websiteRepo.GetByID(websiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.GetByWebsiteID(websiteID)
+}
+
+// RenameTag renames a tag on every page of the website. Renaming onto an
+// existing tag is refused; merge the tags instead.
+func (s *TagService) RenameTag(websiteID int, req *models.RenameTagRequest) (*models.Tag, error) {
+	to := strings.TrimSpace(req.To)
+	if to == "" {
+		return nil, invalidField("to", "required", "cannot be empty")
+	}
+
+	tag, err := s.tagRepo.GetByName(websiteID, strings.TrimSpace(req.From))
+	if err != nil {
+		return nil, err
+	}
+	if tag.Name == to {
+		return tag, nil
+	}
+
+	existing, _ := s.tagRepo.GetByName(websiteID, to)
+	if existing != nil {
+		return nil, &ConflictError{Code: CodeTagExists, Message: fmt.Sprintf("tag %s already exists; merge the tags instead", to)}
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		return s.tagRepo.WithTx(tx).Rename(tag.ID, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.GetByName(websiteID, to)
+}
+
+// MergeTags replaces the source tags with the target tag on every page of
+// the website. The target tag is created if it does not exist yet.
+func (s *TagService) MergeTags(websiteID int, req *models.MergeTagsRequest) (*models.Tag, error) {
+	target := strings.TrimSpace(req.Target)
+	if target == "" {
+		return nil, invalidField("target", "required", "cannot be empty")
+	}
+
+	var sources []*models.Tag
+	for _, name := range req.Sources {
+		name = strings.TrimSpace(name)
+		if name == target {
+			continue
+		}
+		tag, err := s.tagRepo.GetByName(websiteID, name)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", name, err)
+		}
+		sources = append(sources, tag)
+	}
+
+	err := s.txManager.WithTx(func(tx *sql.Tx) error {
+		tagRepo := s.tagRepo.WithTx(tx)
+
+		targetTag, err := tagRepo.GetByName(websiteID, target)
+		if err != nil {
+			targetTag = &models.Tag{WebsiteID: websiteID, Name: target}
+			if err := tagRepo.Create(targetTag); err != nil {
+				return err
+			}
+		}
+
+		for _, source := range sources {
+			if err := tagRepo.Merge(source.ID, targetTag.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.GetByName(websiteID, target)
+}
+
+// normalizeTags trims tag names and drops empty and duplicate entries,
+// keeping the first occurrence of each tag.
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/settings"
+	"github.com/xeodocs/xeodocs-dash-api/internal/storage"
+)
+
+// TrashService lists and restores deleted websites, pages and users and
+// purges them for good once the trash.retention setting has passed.
+type TrashService struct {
+	trashRepo    *repository.TrashRepository
+	websiteRepo  *repository.WebsiteRepository
+	pageRepo     *repository.PageRepository
+	userRepo     *repository.UserRepository
+	assetRepo    *repository.AssetRepository
+	linkRepo     *repository.LinkRepository
+	redirectRepo *repository.RedirectRepository
+	txManager    *repository.TxManager
+	settings     *SettingsService
+	store        storage.BlobStore
+}
+
+func NewTrashService(trashRepo *repository.TrashRepository, websiteRepo *repository.WebsiteRepository,
+	pageRepo *repository.PageRepository, userRepo *repository.UserRepository, assetRepo *repository.AssetRepository,
+	linkRepo *repository.LinkRepository, redirectRepo *repository.RedirectRepository, txManager *repository.TxManager,
+	settings *SettingsService, store storage.BlobStore) *TrashService {
+	return &TrashService{
+		trashRepo:    trashRepo,
+		websiteRepo:  websiteRepo,
+		pageRepo:     pageRepo,
+		userRepo:     userRepo,
+		assetRepo:    assetRepo,
+		linkRepo:     linkRepo,
+		redirectRepo: redirectRepo,
+		txManager:    txManager,
+		settings:     settings,
+		store:        store,
+	}
+}
+
+// GetTrash lists the items in the trash, optionally of one type, with the
+// time they will be purged.
+func (s *TrashService) GetTrash(itemType string) ([]*models.TrashItem, error) {
+	if itemType != "" {
+		if err := checkTrashType(itemType); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := s.trashRepo.GetAll(itemType)
+	if err != nil {
+		return nil, err
+	}
+	retention := s.settings.Duration(settings.TrashRetention)
+	for _, item := range items {
+		item.PurgeAt = item.DeletedAt.Add(retention)
+	}
+	return items, nil
+}
+
+// Restore takes an item out of the trash. Slugs, names and emails freed by
+// the deletion must still be free; a page can only be restored while its
+// website is not in the trash.
+func (s *TrashService) Restore(itemType string, id int) error {
+	if err := checkTrashType(itemType); err != nil {
+		return err
+	}
+
+	switch itemType {
+	case models.TrashTypeWebsite:
+		return s.restoreWebsite(id)
+	case models.TrashTypePage:
+		return s.restorePage(id)
+	default:
+		return s.restoreUser(id)
+	}
+}
+
+func (s *TrashService) restoreWebsite(id int) error {
+	website, err := s.websiteRepo.GetTrashedByID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.websiteRepo.GetBySlug(website.Slug); err == nil {
+		return &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("website with slug %s already exists", website.Slug)}
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if _, err := s.websiteRepo.GetByName(website.Name); err == nil {
+		return &ConflictError{Code: CodeConflict, Message: fmt.Sprintf("website with name %s already exists", website.Name)}
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		websiteRepo := s.websiteRepo.WithTx(tx)
+		pageRepo := s.pageRepo.WithTx(tx)
+		redirectRepo := s.redirectRepo.WithTx(tx)
+
+		// The pages deleted with the website come back with it
+		pages, err := pageRepo.GetTrashedWithWebsite(id)
+		if err != nil {
+			return err
+		}
+		var taken []string
+		for _, page := range pages {
+			if _, err := pageRepo.GetBySlug(page.Slug); err == nil {
+				taken = append(taken, page.Slug)
+			} else if !errors.Is(err, ErrNotFound) {
+				return err
+			}
+		}
+		if len(taken) > 0 {
+			return &ConflictError{Code: CodeSlugTaken,
+				Message: "pages of the website have slugs that are now taken: " + strings.Join(taken, ", ")}
+		}
+
+		if err := websiteRepo.Restore(id); err != nil {
+			return err
+		}
+		// The slugs are live again, so they can no longer redirect elsewhere
+		if err := redirectRepo.DeleteBySlug(models.RedirectTypeWebsite, website.Slug); err != nil {
+			return err
+		}
+		for _, page := range pages {
+			if err := redirectRepo.DeleteBySlug(models.RedirectTypePage, page.Slug); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *TrashService) restorePage(id int) error {
+	page, err := s.pageRepo.GetTrashedByID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.websiteRepo.GetByID(page.WebsiteID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return &ConflictError{Code: CodeConflict, Message: "the website of the page is in the trash; restore the website instead"}
+		}
+		return err
+	}
+	if _, err := s.pageRepo.GetBySlug(page.Slug); err == nil {
+		return &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("page with slug %s already exists", page.Slug)}
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.pageRepo.WithTx(tx).Restore(id); err != nil {
+			return err
+		}
+		if err := s.redirectRepo.WithTx(tx).DeleteBySlug(models.RedirectTypePage, page.Slug); err != nil {
+			return err
+		}
+		// Outbound links were dropped when the page was deleted
+		return s.linkRepo.WithTx(tx).ReplaceForPage(page.ID, extractPageLinks(page))
+	})
+}
+
+func (s *TrashService) restoreUser(id int) error {
+	user, err := s.userRepo.GetTrashedByID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.userRepo.GetByEmail(user.Email); err == nil {
+		return &ConflictError{Code: CodeEmailTaken, Message: fmt.Sprintf("user with email %s already exists", user.Email)}
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		return s.userRepo.WithTx(tx).Restore(id)
+	})
+}
+
+// PurgeExpired deletes the items whose retention has passed for good,
+// including the pages, links, redirects and asset blobs of websites and
+// pages. Each item is purged in its own transaction; it returns how many
+// were purged.
+func (s *TrashService) PurgeExpired() (int, error) {
+	cutoff := time.Now().Add(-s.settings.Duration(settings.TrashRetention))
+	items, err := s.trashRepo.GetDeletedBefore(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, item := range items {
+		var blobs []string
+		err := s.txManager.WithTx(func(tx *sql.Tx) error {
+			var err error
+			blobs, err = s.purge(tx, item)
+			return err
+		})
+		if errors.Is(err, ErrNotFound) {
+			// Restored or purged meanwhile
+			continue
+		}
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge %s %d: %w", item.Type, item.ID, err)
+		}
+		purged++
+		s.deleteBlobs(blobs)
+	}
+	if purged > 0 {
+		log.Printf("Purged %d item(s) from the trash", purged)
+	}
+	return purged, nil
+}
+
+// purge deletes an item in the trash and returns the asset blobs to remove.
+func (s *TrashService) purge(tx *sql.Tx, item *models.TrashItem) ([]string, error) {
+	switch item.Type {
+	case models.TrashTypeWebsite:
+		if _, err := s.websiteRepo.WithTx(tx).GetTrashedByID(item.ID); err != nil {
+			return nil, err
+		}
+		return s.purgeWebsite(tx, item.ID)
+	case models.TrashTypePage:
+		if _, err := s.pageRepo.WithTx(tx).GetTrashedByID(item.ID); err != nil {
+			return nil, err
+		}
+		return s.purgePage(tx, item.ID)
+	default:
+		if _, err := s.userRepo.WithTx(tx).GetTrashedByID(item.ID); err != nil {
+			return nil, err
+		}
+		return nil, s.userRepo.WithTx(tx).Delete(item.ID)
+	}
+}
+
+// purgeWebsite deletes a website, in the trash or not, with every page,
+// asset, link, tag and redirect and returns the blob keys of its assets.
+// Dependent rows go first, so it does not rely on foreign key actions.
+func (s *TrashService) purgeWebsite(tx *sql.Tx, id int) ([]string, error) {
+	pageIDs, err := s.pageRepo.WithTx(tx).GetIDsByWebsite(id)
+	if err != nil {
+		return nil, err
+	}
+	var blobs []string
+	for _, pageID := range pageIDs {
+		keys, err := s.purgePage(tx, pageID)
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, keys...)
+	}
+	if err := s.redirectRepo.WithTx(tx).DeleteByWebsite(id); err != nil {
+		return nil, err
+	}
+	return blobs, s.websiteRepo.WithTx(tx).Delete(id)
+}
+
+// purgePage deletes a page with its assets, links and redirects and returns
+// the blob keys of its assets.
+func (s *TrashService) purgePage(tx *sql.Tx, id int) ([]string, error) {
+	assetRepo := s.assetRepo.WithTx(tx)
+	assets, err := assetRepo.GetByPageID(id)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		blobs = append(blobs, asset.BucketKey)
+	}
+	if err := assetRepo.DeleteByPageID(id); err != nil {
+		return nil, err
+	}
+	if err := s.linkRepo.WithTx(tx).DeleteBySourcePage(id); err != nil {
+		return nil, err
+	}
+	if err := s.redirectRepo.WithTx(tx).DeleteByTarget(models.RedirectTypePage, id); err != nil {
+		return nil, err
+	}
+	return blobs, s.pageRepo.WithTx(tx).Delete(id)
+}
+
+// deleteBlobs removes the blobs of purged assets. It runs only after the
+// commit, so a failed purge keeps them; failures are logged.
+func (s *TrashService) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(key); err != nil {
+			log.Printf("Failed to remove purged blob %s: %v", key, err)
+		}
+	}
+}
+
+// checkTrashType checks the type of a trash item named in a request.
+func checkTrashType(itemType string) error {
+	switch itemType {
+	case models.TrashTypeWebsite, models.TrashTypePage, models.TrashTypeUser:
+		return nil
+	}
+	return invalidRequest(fmt.Sprintf("unknown trash type %q; use website, page or user", itemType))
+}
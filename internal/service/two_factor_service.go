@@ -0,0 +1,419 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/totp"
+)
+
+const (
+	// TwoFactorChallengeTTL bounds how long the second step of a login may
+	// take
+	TwoFactorChallengeTTL = 5 * time.Minute
+
+	// maxChallengeAttempts is how many wrong codes a login challenge takes
+	// before the login must start over
+	maxChallengeAttempts = 5
+
+	// recoveryCodeCount is the size of a set of recovery codes
+	recoveryCodeCount = 10
+)
+
+// recoveryCodeAlphabet is Crockford's base32, which leaves out letters
+// easily confused when codes are typed from paper. Its 32 symbols map
+// random bytes without bias.
+const recoveryCodeAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// TwoFactorService manages TOTP two-factor authentication: enrollment,
+// recovery codes, the per-role requirement and login challenges.
+type TwoFactorService struct {
+	twoFactorRepo *repository.TwoFactorRepository
+	userRepo      *repository.UserRepository
+	txManager     *repository.TxManager
+	// issuer names the service in authenticator apps
+	issuer string
+}
+
+func NewTwoFactorService(twoFactorRepo *repository.TwoFactorRepository, userRepo *repository.UserRepository,
+	txManager *repository.TxManager, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		txManager:     txManager,
+		issuer:        issuer,
+	}
+}
+
+// GetStatus describes the two-factor authentication of a user.
+func (s *TwoFactorService) GetStatus(userID int) (*models.TwoFactorStatus, error) {
+	status := &models.TwoFactorStatus{}
+
+	enrollment, err := s.enabledTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment != nil {
+		status.Enabled = true
+		status.EnabledAt = enrollment.EnabledAt
+		status.RecoveryCodesRemaining, err = s.twoFactorRepo.CountRecoveryCodes(userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	status.Required, err = s.twoFactorRepo.RoleRequiresTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Setup starts a TOTP enrollment with a new secret. The enrollment takes
+// effect once Enable confirms a code; calling Setup again replaces the
+// pending secret.
+func (s *TwoFactorService) Setup(userID int) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.enabledTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment != nil {
+		return nil, &ConflictError{Message: "two-factor authentication is already enabled; disable it first to enroll a new device"}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	if err := s.twoFactorRepo.SavePendingTOTP(&models.UserTOTP{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable completes a pending enrollment with a code from the authenticator
+// app and returns the user's recovery codes.
+func (s *TwoFactorService) Enable(userID int, code string) ([]string, error) {
+	enrollment, err := s.twoFactorRepo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, &ConflictError{Message: "no enrollment in progress; start one with POST /auth/2fa/setup"}
+		}
+		return nil, err
+	}
+	if enrollment.EnabledAt != nil {
+		return nil, &ConflictError{Message: "two-factor authentication is already enabled"}
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now(), 0)
+	if !ok {
+		return nil, invalidField("code", "incorrect", "is incorrect")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		twoFactorRepo := s.twoFactorRepo.WithTx(tx)
+
+		if err := twoFactorRepo.EnableTOTP(userID, step); err != nil {
+			return err
+		}
+		if err := twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), userID, models.ActivityTwoFactorEnabled, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off after checking a current
+// code or a recovery code. Users whose role requires it cannot.
+func (s *TwoFactorService) Disable(userID int, code string) error {
+	required, err := s.twoFactorRepo.RoleRequiresTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return &ForbiddenError{Message: "a role of yours requires two-factor authentication"}
+	}
+	if err := s.checkCode(userID, code); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.remove(s.twoFactorRepo.WithTx(tx), userID); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), userID, models.ActivityTwoFactorDisabled, nil)
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after
+// checking a current code or a recovery code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	if err := s.checkCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.twoFactorRepo.WithTx(tx).ReplaceRecoveryCodes(userID, hashes); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), userID, models.ActivityRecoveryCodesRenewed, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Reset turns off the two-factor authentication of a user on behalf of an
+// admin, e.g. after the user lost the device and the recovery codes.
+func (s *TwoFactorService) Reset(adminID, userID int) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.remove(s.twoFactorRepo.WithTx(tx), userID); err != nil {
+			return err
+		}
+		return logActivity(s.userRepo.WithTx(tx), userID, models.ActivityTwoFactorReset, map[string]interface{}{"byUserId": adminID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// remove deletes the enrollment, recovery codes and pending login
+// challenges of a user.
+func (s *TwoFactorService) remove(twoFactorRepo *repository.TwoFactorRepository, userID int) error {
+	if err := twoFactorRepo.DeleteTOTP(userID); err != nil {
+		return err
+	}
+	if err := twoFactorRepo.DeleteRecoveryCodes(userID); err != nil {
+		return err
+	}
+	return twoFactorRepo.DeleteUserChallenges(userID)
+}
+
+// SetRoleRequirement makes two-factor authentication required for the
+// users of a role, or not. The change is recorded in the admin's log.
+func (s *TwoFactorService) SetRoleRequirement(adminID, roleID int, required bool) (*models.Role, error) {
+	role, err := s.userRepo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		if err := userRepo.SetRoleRequireTwoFactor(role, required); err != nil {
+			return err
+		}
+		return logActivity(userRepo, adminID, models.ActivityRoleTwoFactorChanged,
+			map[string]interface{}{"roleId": role.ID, "role": role.Name, "required": required})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// EnrollmentRequired reports whether a user must enroll in two-factor
+// authentication before using the API.
+func (s *TwoFactorService) EnrollmentRequired(userID int) (bool, error) {
+	return s.twoFactorRepo.EnrollmentRequired(userID)
+}
+
+// enabledTOTP returns the enabled enrollment of a user, or nil.
+func (s *TwoFactorService) enabledTOTP(userID int) (*models.UserTOTP, error) {
+	enrollment, err := s.twoFactorRepo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if enrollment.EnabledAt == nil {
+		return nil, nil
+	}
+	return enrollment, nil
+}
+
+// checkCode verifies a code of a signed-in user for account changes.
+func (s *TwoFactorService) checkCode(userID int, code string) error {
+	enrollment, err := s.enabledTOTP(userID)
+	if err != nil {
+		return err
+	}
+	if enrollment == nil {
+		return &ConflictError{Message: "two-factor authentication is not enabled"}
+	}
+
+	ok, err := s.verifyCode(enrollment, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return invalidField("code", "incorrect", "is incorrect")
+	}
+	return nil
+}
+
+// verifyCode checks a code from the authenticator app or an unused
+// recovery code of an enabled enrollment and consumes it.
+func (s *TwoFactorService) verifyCode(enrollment *models.UserTOTP, code string) (bool, error) {
+	if step, ok := totp.Validate(enrollment.Secret, code, time.Now(), enrollment.LastStep); ok {
+		err := s.twoFactorRepo.UseTOTPStep(enrollment.UserID, step)
+		if errors.Is(err, ErrNotFound) {
+			// A concurrent request used the code first
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	err := s.twoFactorRepo.UseRecoveryCode(enrollment.UserID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	remaining, err := s.twoFactorRepo.CountRecoveryCodes(enrollment.UserID)
+	if err != nil {
+		return false, err
+	}
+	err = logActivity(s.userRepo, enrollment.UserID, models.ActivityRecoveryCodeUsed, map[string]interface{}{"remaining": remaining})
+	return err == nil, err
+}
+
+// challenge starts the second step of a login for a user with two-factor
+// authentication, if the user has it enabled.
+func (s *TwoFactorService) challenge(userID int) (*models.LoginResponse, error) {
+	enrollment, err := s.enabledTOTP(userID)
+	if err != nil || enrollment == nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+
+	// Abandoned logins would otherwise pile up; failing to prune is harmless
+	_ = s.twoFactorRepo.DeleteExpiredChallenges()
+
+	challenge := &models.LoginChallenge{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(TwoFactorChallengeTTL),
+	}
+	if err := s.twoFactorRepo.CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresAt:         challenge.ExpiresAt,
+	}, nil
+}
+
+// getChallenge returns the unexpired login challenge of a challenge token.
+func (s *TwoFactorService) getChallenge(token string) (*models.LoginChallenge, error) {
+	challenge, err := s.twoFactorRepo.GetChallengeByHash(hashToken(token))
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrLoginChallengeExpired
+	}
+	return challenge, err
+}
+
+// answerChallenge checks the code for a login challenge. A correct code
+// consumes the challenge; wrong codes use up its attempts.
+func (s *TwoFactorService) answerChallenge(challenge *models.LoginChallenge, code string) (bool, error) {
+	enrollment, err := s.enabledTOTP(challenge.UserID)
+	if err != nil {
+		return false, err
+	}
+	if enrollment == nil {
+		// Two-factor authentication was reset meanwhile
+		return false, ErrLoginChallengeExpired
+	}
+
+	ok, err := s.verifyCode(enrollment, code)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		if challenge.Attempts+1 >= maxChallengeAttempts {
+			err = s.twoFactorRepo.DeleteChallenge(challenge.ID)
+		} else {
+			err = s.twoFactorRepo.CountChallengeAttempt(challenge.ID)
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if err := s.twoFactorRepo.DeleteChallenge(challenge.ID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			// Another request completed the login first
+			return false, ErrLoginChallengeExpired
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// newRecoveryCodes returns a set of recovery codes and their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		for j := range b {
+			b[j] = recoveryCodeAlphabet[b[j]%32]
+		}
+		codes[i] = string(b[:5]) + "-" + string(b[5:])
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+	return codes, hashes, nil
+}
+
+// recoveryCodeReplacer drops separators from typed recovery codes and
+// reads the left-out letters as the digits they resemble.
+var recoveryCodeReplacer = strings.NewReplacer("-", "", " ", "", "o", "0", "i", "1", "l", "1")
+
+func normalizeRecoveryCode(code string) string {
+	return recoveryCodeReplacer.Replace(strings.ToLower(code))
+}
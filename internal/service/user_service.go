@@ -1,30 +1,95 @@
 package service
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/xeodocs/xeodocs-dash-api/internal/mailer"
 	"github.com/xeodocs/xeodocs-dash-api/internal/models"
 	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/settings"
 )
 
+// The admin account seeded by the initial data migration, with its
+// well-known password.
+const (
+	DefaultAdminEmail    = "admin@xeodocs.com"
+	DefaultAdminPassword = "pass12345"
+)
+
+// dummyPasswordHash is compared against when a login names no password
+// account, so that unknown emails take as long as wrong passwords.
+var dummyPasswordHash = []byte("$2a$10$LFQslN45Aw2zEx4s1710i.xv1zE8m2M1fEI4VVFJ9Oac15OoT/OMS")
+
+// sessionTouchInterval limits how often the last-seen time and the sliding
+// expiry of a session are written while it is in use.
+const sessionTouchInterval = time.Minute
+
+// ClientInfo describes the client making a request, for session records
+// and login throttling.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// AuthOptions configures authentication in the UserService.
+type AuthOptions struct {
+	// PasswordResetURL is the page of the frontend that completes a reset;
+	// the token is added as the "token" query parameter
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+
+	// DisablePasswordLogin refuses password logins, changes and resets when
+	// users must sign in through single sign-on
+	DisablePasswordLogin bool
+}
+
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo     *repository.UserRepository
+	apiTokenRepo *repository.APITokenRepository
+	txManager    *repository.TxManager
+	throttle     *LoginThrottle
+	twoFactor    *TwoFactorService
+	settings     *SettingsService
+	mailer       mailer.Mailer
+	options      AuthOptions
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo *repository.UserRepository, apiTokenRepo *repository.APITokenRepository,
+	txManager *repository.TxManager, throttle *LoginThrottle, twoFactor *TwoFactorService, settings *SettingsService,
+	mailer mailer.Mailer, options AuthOptions) *UserService {
+	return &UserService{
+		userRepo:     userRepo,
+		apiTokenRepo: apiTokenRepo,
+		txManager:    txManager,
+		throttle:     throttle,
+		twoFactor:    twoFactor,
+		settings:     settings,
+		mailer:       mailer,
+		options:      options,
+	}
 }
 
 func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.User, error) {
 	// Check if user already exists
 	existingUser, _ := s.userRepo.GetByEmail(req.Email)
 	if existingUser != nil {
-		return nil, fmt.Errorf("user with email %s already exists", req.Email)
+		return nil, &ConflictError{Code: CodeEmailTaken, Message: fmt.Sprintf("user with email %s already exists", req.Email)}
+	}
+	if err := s.checkPasswordPolicy("password", req.Password); err != nil {
+		return nil, err
 	}
 
 	// Hash password
@@ -55,61 +120,304 @@ func (s *UserService) GetAllUsers() ([]*models.User, error) {
 	return s.userRepo.GetAll()
 }
 
-func (s *UserService) UpdateUser(id int, req *models.UpdateUserRequest) (*models.User, error) {
+// UpdateUser applies the provided fields to a user on behalf of actorID,
+// who must be the user or an admin. A non-zero expectedVersion makes the
+// update conditional on the user's version.
+func (s *UserService) UpdateUser(actorID, id int, req *models.UpdateUserRequest, expectedVersion int) (*models.User, error) {
+	if err := s.authorizeUserChange(actorID, id); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion("user", user.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	oldEmail := user.Email
 
 	// Update fields if provided
 	if req.Email != "" {
-		// Check if new email is already taken by another user
-		existingUser, _ := s.userRepo.GetByEmail(req.Email)
-		if existingUser != nil && existingUser.ID != id {
-			return nil, fmt.Errorf("email %s is already taken", req.Email)
-		}
 		user.Email = req.Email
 	}
 	if req.Name != "" {
 		user.Name = req.Name
 	}
 
-	err = s.userRepo.Update(id, user)
+	return s.saveUpdatedUser(user, oldEmail)
+}
+
+// PatchUser applies a JSON Merge Patch (RFC 7396) to a user's profile
+// fields on behalf of actorID, who must be the user or an admin, and
+// validates the result like a new user. A non-zero expectedVersion makes
+// the update conditional on the user's version.
+func (s *UserService) PatchUser(actorID, id int, patch []byte, expectedVersion int) (*models.User, error) {
+	if err := s.authorizeUserChange(actorID, id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("user", user.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	doc := &models.UserProfile{
+		Email: user.Email,
+		Name:  user.Name,
+	}
+	if err := applyMergePatch(doc, patch); err != nil {
+		return nil, err
+	}
+
+	oldEmail := user.Email
+	user.Email = doc.Email
+	user.Name = doc.Name
+
+	return s.saveUpdatedUser(user, oldEmail)
+}
+
+// authorizeUserChange lets admins change every user and other users only
+// themselves. Password resets are mailed to the stored email, so changing
+// another user's email would take over the account.
+func (s *UserService) authorizeUserChange(actorID, userID int) error {
+	if actorID == userID {
+		return nil
+	}
+	admin, err := s.HasRole(actorID, "admin")
 	if err != nil {
+		return err
+	}
+	if !admin {
+		return &ForbiddenError{Message: "only admins can change other users"}
+	}
+	return nil
+}
+
+// saveUpdatedUser stores a modified user after checking that a changed
+// email is still free.
+func (s *UserService) saveUpdatedUser(user *models.User, oldEmail string) (*models.User, error) {
+	if user.Email != oldEmail {
+		// Check if new email is already taken by another user
+		existingUser, _ := s.userRepo.GetByEmail(user.Email)
+		if existingUser != nil && existingUser.ID != user.ID {
+			return nil, &ConflictError{Code: CodeEmailTaken, Message: fmt.Sprintf("email %s is already taken", user.Email)}
+		}
+	}
+
+	if err := s.userRepo.Update(user.ID, user); err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (s *UserService) DeleteUser(id int) error {
-	return s.userRepo.Delete(id)
+// DeleteUser moves a user to the trash and ends their sessions. A non-zero
+// expectedVersion makes the deletion conditional on the user's version.
+// actorID must be the user or an admin.
+func (s *UserService) DeleteUser(actorID, id, expectedVersion int) error {
+	if err := s.authorizeUserChange(actorID, id); err != nil {
+		return err
+	}
+
+	if expectedVersion != 0 {
+		user, err := s.userRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("user", user.Version, expectedVersion); err != nil {
+			return err
+		}
+	}
+
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+		if err := userRepo.Trash(id, time.Now()); err != nil {
+			return err
+		}
+		// A user in the trash cannot log in; API tokens stop working with it
+		_, err := userRepo.DeleteSessionsByUser(id)
+		return err
+	})
 }
 
-func (s *UserService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
+// Login starts a session for valid credentials, or for users with
+// two-factor authentication returns a challenge that
+// CompleteTwoFactorLogin completes. Failed attempts are counted per account
+// and per client IP; too many of them make Login return a *ThrottledError
+// until the backoff or lockout expires.
+func (s *UserService) Login(req *models.LoginRequest, client ClientInfo) (*models.LoginResponse, error) {
+	if err := s.checkPasswordLogin(); err != nil {
+		return nil, err
+	}
+
+	accountKey, ipKey := accountThrottleKey(req.Email), ipThrottleKey(client.IP)
+	if err := s.throttle.Check(accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, s.loginFailed(nil, accountKey, ipKey, client.IP)
 	}
 
 	// Check password
+	if user.PasswordHash == "" {
+		// Users provisioned through single sign-on have no password
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, s.loginFailed(user, accountKey, ipKey, client.IP)
+	}
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		return nil, s.loginFailed(user, accountKey, ipKey, client.IP)
+	}
+
+	response, err := s.beginSession(user, client)
+	if err != nil {
+		return nil, err
+	}
+	// With a challenge the failures keep counting until the code is right too
+	if !response.TwoFactorRequired {
+		if err := s.throttle.Reset(accountKey); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// CompleteTwoFactorLogin finishes a login that returned a challenge, with a
+// code from the authenticator app or a recovery code. Wrong codes count as
+// failed logins of the account.
+func (s *UserService) CompleteTwoFactorLogin(req *models.TwoFactorLoginRequest, client ClientInfo) (*models.LoginResponse, error) {
+	challenge, err := s.twoFactor.getChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	accountKey, ipKey := accountThrottleKey(user.Email), ipThrottleKey(client.IP)
+	if err := s.throttle.Check(accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.twoFactor.answerChallenge(challenge, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.recordLoginFailure(user, accountKey, ipKey, client.IP); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.throttle.Reset(accountKey); err != nil {
+		return nil, err
+	}
+	return s.createSession(user, client)
+}
+
+// beginSession starts a session for a user who has proved the first
+// factor, or returns a login challenge if the user has two-factor
+// authentication enabled.
+func (s *UserService) beginSession(user *models.User, client ClientInfo) (*models.LoginResponse, error) {
+	response, err := s.twoFactor.challenge(user.ID)
+	if err != nil || response != nil {
+		return response, err
+	}
+	return s.createSession(user, client)
+}
+
+// checkPasswordLogin refuses password operations when users must sign in
+// through single sign-on.
+func (s *UserService) checkPasswordLogin() error {
+	if s.options.DisablePasswordLogin {
+		return &ForbiddenError{Message: "password login is disabled; sign in with single sign-on"}
+	}
+	return nil
+}
+
+// loginFailed counts a failed password login and returns the error for it.
+func (s *UserService) loginFailed(user *models.User, accountKey, ipKey, clientIP string) error {
+	if err := s.recordLoginFailure(user, accountKey, ipKey, clientIP); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
+// recordLoginFailure counts a failed login and records it in the activity
+// log of the account, if it exists. Unknown emails are counted too, so
+// lockouts do not reveal which accounts exist.
+func (s *UserService) recordLoginFailure(user *models.User, accountKey, ipKey, clientIP string) error {
+	account, locked, err := s.throttle.RecordFailure(accountKey, s.throttle.options.MaxFailures)
+	if err != nil {
+		return err
 	}
+	if _, _, err := s.throttle.RecordFailure(ipKey, s.throttle.options.IPMaxFailures); err != nil {
+		return err
+	}
+
+	if user != nil {
+		details := map[string]interface{}{"ip": clientIP, "failures": account.Failures}
+		if err := logActivity(s.userRepo, user.ID, models.ActivityLoginFailed, details); err != nil {
+			return err
+		}
+		if locked {
+			details := map[string]interface{}{"ip": clientIP, "lockedUntil": account.LockedUntil}
+			if err := logActivity(s.userRepo, user.ID, models.ActivityAccountLocked, details); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
+// UnlockUser clears the failed logins and any lockout of a user's account.
+func (s *UserService) UnlockUser(adminID, userID int) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.throttle.Reset(accountThrottleKey(user.Email)); err != nil {
+		return nil, err
+	}
+	if err := logActivity(s.userRepo, user.ID, models.ActivityAccountUnlocked, map[string]interface{}{"byUserId": adminID}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createSession starts a new session for an authenticated user.
+func (s *UserService) createSession(user *models.User, client ClientInfo) (*models.LoginResponse, error) {
 	// Generate session token
-	sessionToken, err := s.generateSessionToken()
+	sessionToken, err := generateToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate session token: %w", err)
 	}
 
-	// Create session
+	// Create session; only the token hash is stored
+	now := time.Now()
+	absoluteExpiresAt := now.Add(s.settings.Duration(settings.SessionMaxLifetime))
 	session := &models.UserSession{
-		UserID:       user.ID,
-		SessionToken: sessionToken,
-		ExpiresAt:    time.Now().Add(24 * time.Hour), // 24 hours expiry
+		UserID:            user.ID,
+		TokenHash:         hashToken(sessionToken),
+		UserAgent:         client.UserAgent,
+		IPAddress:         client.IP,
+		ExpiresAt:         s.slidingExpiry(now, absoluteExpiresAt),
+		AbsoluteExpiresAt: absoluteExpiresAt,
 	}
 
 	err = s.userRepo.CreateSession(session)
@@ -118,34 +426,133 @@ func (s *UserService) Login(req *models.LoginRequest) (*models.LoginResponse, er
 	}
 
 	return &models.LoginResponse{
-		User:         *user,
+		User:         user,
 		SessionToken: sessionToken,
+		ExpiresAt:    session.ExpiresAt,
 	}, nil
 }
 
+// touchInterval returns how often a session in use is touched: every
+// sessionTouchInterval, or more often for short idle timeouts so activity
+// always renews the session before it expires.
+func (s *UserService) touchInterval() time.Duration {
+	if half := s.settings.Duration(settings.SessionIdleTimeout) / 2; half < sessionTouchInterval {
+		return half
+	}
+	return sessionTouchInterval
+}
+
+// slidingExpiry returns when a session active at now expires: after the
+// idle timeout, but not after its absolute expiry.
+func (s *UserService) slidingExpiry(now, absoluteExpiresAt time.Time) time.Time {
+	expiresAt := now.Add(s.settings.Duration(settings.SessionIdleTimeout))
+	if expiresAt.After(absoluteExpiresAt) {
+		return absoluteExpiresAt
+	}
+	return expiresAt
+}
+
 func (s *UserService) Logout(sessionToken string) error {
-	return s.userRepo.DeleteSession(sessionToken)
+	return s.userRepo.DeleteSessionByTokenHash(hashToken(sessionToken))
 }
 
-func (s *UserService) ValidateSession(sessionToken string) (*models.User, error) {
-	session, err := s.userRepo.GetSessionByToken(sessionToken)
+// ValidateSession returns the user and the session of a session token,
+// records the activity on the session and renews its idle expiry.
+func (s *UserService) ValidateSession(sessionToken string, client ClientInfo) (*models.User, *models.UserSession, error) {
+	session, err := s.userRepo.GetSessionByTokenHash(hashToken(sessionToken))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= s.touchInterval() || session.IPAddress != client.IP {
+		touched := *session
+		touched.LastSeenAt = now
+		touched.IPAddress = client.IP
+		touched.ExpiresAt = s.slidingExpiry(now, session.AbsoluteExpiresAt)
+
+		// Best effort: activity tracking must not fail the request
+		if err := s.userRepo.TouchSession(&touched); err == nil {
+			session = &touched
+		}
+	}
+
+	return user, session, nil
+}
+
+// GetSessions lists the active sessions of a user, marking the current one.
+func (s *UserService) GetSessions(userID, currentSessionID int) ([]*models.UserSession, error) {
+	sessions, err := s.userRepo.GetSessionsByUser(userID)
 	if err != nil {
 		return nil, err
 	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+	return sessions, nil
+}
 
-	return user, nil
+// RevokeSession ends one of the user's own sessions.
+func (s *UserService) RevokeSession(userID, sessionID int) error {
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		if err := userRepo.DeleteUserSession(userID, sessionID); err != nil {
+			return err
+		}
+		return logActivity(userRepo, userID, models.ActivitySessionRevoked, map[string]interface{}{"sessionId": sessionID})
+	})
+}
+
+// RevokeOtherSessions ends every session of the user except the current
+// one ("log out everywhere else") and returns how many were ended.
+func (s *UserService) RevokeOtherSessions(userID, currentSessionID int) (int, error) {
+	var revoked int
+	err := s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		var err error
+		revoked, err = userRepo.DeleteOtherSessions(userID, currentSessionID)
+		if err != nil {
+			return err
+		}
+		return logActivity(userRepo, userID, models.ActivitySessionsRevoked, map[string]interface{}{"revoked": revoked, "exceptCurrent": true})
+	})
+	return revoked, err
+}
+
+// RevokeAllSessions ends every session of a user on behalf of an admin and
+// returns how many were ended.
+func (s *UserService) RevokeAllSessions(adminID, userID int) (int, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return 0, err
+	}
+
+	var revoked int
+	err := s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		var err error
+		revoked, err = userRepo.DeleteSessionsByUser(userID)
+		if err != nil {
+			return err
+		}
+		return logActivity(userRepo, userID, models.ActivitySessionsRevoked, map[string]interface{}{"revoked": revoked, "byUserId": adminID})
+	})
+	return revoked, err
 }
 
 func (s *UserService) CleanupExpiredSessions() error {
 	return s.userRepo.DeleteExpiredSessions()
 }
 
-func (s *UserService) generateSessionToken() (string, error) {
+// generateToken returns a random 256-bit token in hex.
+func generateToken() (string, error) {
 	bytes := make([]byte, 32)
 	_, err := rand.Read(bytes)
 	if err != nil {
@@ -153,3 +560,293 @@ func (s *UserService) generateSessionToken() (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// hashToken returns the SHA-256 of a token in hex, the form in which
+// tokens are stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CSRFToken returns the CSRF token of a session for cookie-authenticated
+// requests: an HMAC keyed with the session token, so it needs no storage
+// and cannot be derived without the session token.
+func CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(sessionToken))
+	mac.Write([]byte("csrf"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ChangePassword replaces the password of a user after verifying the
+// current one. Every session of the user is ended; the caller gets a new
+// one.
+func (s *UserService) ChangePassword(userID int, req *models.ChangePasswordRequest, client ClientInfo) (*models.LoginResponse, error) {
+	if err := s.checkPasswordLogin(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)) != nil {
+		return nil, invalidField("currentPassword", "incorrect", "is incorrect")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return nil, invalidField("newPassword", "unchanged", "must differ from the current password")
+	}
+	if err := s.checkPasswordPolicy("newPassword", req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.setPassword(user, req.NewPassword, models.ActivityPasswordChanged, nil); err != nil {
+		return nil, err
+	}
+	return s.createSession(user, client)
+}
+
+// RequestPasswordReset emails a single-use reset link to the user with the
+// given email. Unknown emails are ignored, so callers cannot probe which
+// accounts exist. Requests are throttled per email and per client IP like
+// failed logins.
+func (s *UserService) RequestPasswordReset(email string, client ClientInfo) error {
+	if err := s.checkPasswordLogin(); err != nil {
+		return err
+	}
+
+	accountKey, ipKey := resetThrottleKeys(email, client.IP)
+	if err := s.throttle.Check(accountKey, ipKey); err != nil {
+		var throttledErr *ThrottledError
+		if errors.As(err, &throttledErr) {
+			throttledErr.Message = "too many password reset requests; try again later"
+		}
+		return err
+	}
+	// Every request counts, whether or not the account exists
+	if _, _, err := s.throttle.RecordFailure(accountKey, s.throttle.options.MaxFailures); err != nil {
+		return err
+	}
+	if _, _, err := s.throttle.RecordFailure(ipKey, s.throttle.options.IPMaxFailures); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	resetToken := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.options.PasswordResetTTL),
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		// Only the latest link works
+		if err := userRepo.DeleteUnusedPasswordResetTokens(user.ID); err != nil {
+			return err
+		}
+		if err := userRepo.CreatePasswordResetToken(resetToken); err != nil {
+			return err
+		}
+		return logActivity(userRepo, user.ID, models.ActivityPasswordResetRequested, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := url.Parse(s.options.PasswordResetURL)
+	if err != nil {
+		return fmt.Errorf("invalid password reset URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	err = s.mailer.Send(&mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your XeoDocs password",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"A password reset was requested for your XeoDocs account. Open this link to choose a new password:\n\n"+
+			"%s\n\n"+
+			"The link works once and expires on %s. If you did not ask for it, you can ignore this email.\n",
+			user.Name, link.String(), resetToken.ExpiresAt.UTC().Format("2006-01-02 15:04 MST")),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token, which is consumed.
+// Every session of the user is ended.
+func (s *UserService) ResetPassword(req *models.ConfirmPasswordResetRequest) error {
+	if err := s.checkPasswordLogin(); err != nil {
+		return err
+	}
+
+	if err := s.checkPasswordPolicy("newPassword", req.NewPassword); err != nil {
+		return err
+	}
+
+	invalidToken := invalidField("token", "invalid", "is invalid or has expired")
+
+	resetToken, err := s.userRepo.GetPasswordResetTokenByHash(hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return invalidToken
+		}
+		return err
+	}
+	user, err := s.userRepo.GetByID(resetToken.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return invalidToken
+		}
+		return err
+	}
+
+	err = s.setPassword(user, req.NewPassword, models.ActivityPasswordReset, func(userRepo *repository.UserRepository) error {
+		return userRepo.MarkPasswordResetTokenUsed(resetToken.ID)
+	})
+	if errors.Is(err, ErrNotFound) {
+		// The token was used concurrently
+		return invalidToken
+	}
+	return err
+}
+
+// checkPasswordPolicy checks a new password, sent in field, against the
+// password settings.
+func (s *UserService) checkPasswordPolicy(field, password string) error {
+	if minLength := s.settings.Int(settings.PasswordMinLength); utf8.RuneCountInString(password) < minLength {
+		return invalidField(field, "min", fmt.Sprintf("must be at least %d characters long", minLength))
+	}
+	if s.settings.Bool(settings.PasswordRequireDigit) && !strings.ContainsAny(password, "0123456789") {
+		return invalidField(field, "digit", "must contain a digit")
+	}
+	if s.settings.Bool(settings.PasswordRequireMixedCase) &&
+		(strings.ToLower(password) == password || strings.ToUpper(password) == password) {
+		return invalidField(field, "mixed_case", "must contain upper and lower case letters")
+	}
+	return nil
+}
+
+// setPassword stores a new password for a user, clears the must-change
+// flag, ends every session and revokes every API token of the user in one
+// transaction, together with the audit log entry and the optional consume
+// step.
+func (s *UserService) setPassword(user *models.User, password, activity string, consume func(*repository.UserRepository) error) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		if consume != nil {
+			if err := consume(userRepo); err != nil {
+				return err
+			}
+		}
+
+		user.PasswordHash = string(hashedPassword)
+		user.MustChangePassword = false
+		if err := userRepo.UpdatePassword(user); err != nil {
+			return err
+		}
+		if _, err := userRepo.DeleteSessionsByUser(user.ID); err != nil {
+			return err
+		}
+		if err := userRepo.DeleteUnusedPasswordResetTokens(user.ID); err != nil {
+			return err
+		}
+		if _, err := s.apiTokenRepo.WithTx(tx).DeleteByUser(user.ID); err != nil {
+			return err
+		}
+		return logActivity(userRepo, user.ID, activity, nil)
+	})
+}
+
+// ForcePasswordReset makes a user change the password on next login and
+// ends all of the user's sessions and API tokens.
+func (s *UserService) ForcePasswordReset(adminID, userID int) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		if err := userRepo.SetMustChangePassword(user, true); err != nil {
+			return err
+		}
+		if _, err := userRepo.DeleteSessionsByUser(user.ID); err != nil {
+			return err
+		}
+		if _, err := s.apiTokenRepo.WithTx(tx).DeleteByUser(user.ID); err != nil {
+			return err
+		}
+		return logActivity(userRepo, user.ID, models.ActivityPasswordResetForced, map[string]interface{}{"byUserId": adminID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetRoles returns all roles.
+func (s *UserService) GetRoles() ([]*models.Role, error) {
+	return s.userRepo.GetRoles()
+}
+
+// HasRole reports whether a user has the named role.
+func (s *UserService) HasRole(userID int, role string) (bool, error) {
+	roles, err := s.userRepo.GetRoleNames(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range roles {
+		if name == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DefaultCredentialsValid reports whether the seeded admin account still
+// accepts its well-known password.
+func DefaultCredentialsValid(userRepo *repository.UserRepository) (bool, error) {
+	user, err := userRepo.GetByEmail(DefaultAdminEmail)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(DefaultAdminPassword)) == nil, nil
+}
+
+// logActivity records an entry in a user's activity log.
+func logActivity(userRepo *repository.UserRepository, userID int, activity string, details map[string]interface{}) error {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode log details: %w", err)
+	}
+	return userRepo.CreateLog(&models.UserLog{UserID: userID, ActivityType: activity, Details: string(encoded)})
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+)
+
+func TestUpdateUserOtherUsersEmail(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestUserService(t, db, &recordingMailer{})
+	user := createTestUser(t, s, "user@example.com")
+	other := createTestUser(t, s, "other@example.com")
+
+	// A user who is not an admin cannot take over the admin or anyone else
+	for _, id := range []int{seededAdminID, other.ID} {
+		_, err := s.UpdateUser(user.ID, id, &models.UpdateUserRequest{Email: "attacker@example.com"}, 0)
+		var forbidden *ForbiddenError
+		if !errors.As(err, &forbidden) {
+			t.Fatalf("UpdateUser(other user %d) error = %v, want ForbiddenError", id, err)
+		}
+		stored, err := s.GetUserByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Email == "attacker@example.com" {
+			t.Fatalf("email of user %d changed", id)
+		}
+	}
+
+	if _, err := s.UpdateUser(user.ID, user.ID, &models.UpdateUserRequest{Email: "user2@example.com"}, 0); err != nil {
+		t.Fatalf("UpdateUser(self) error = %v", err)
+	}
+	if _, err := s.UpdateUser(seededAdminID, other.ID, &models.UpdateUserRequest{Email: "other2@example.com"}, 0); err != nil {
+		t.Fatalf("UpdateUser(as admin) error = %v", err)
+	}
+}
+
+func TestDeleteOtherUser(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestUserService(t, db, &recordingMailer{})
+	user := createTestUser(t, s, "user@example.com")
+	other := createTestUser(t, s, "other@example.com")
+
+	var forbidden *ForbiddenError
+	if err := s.DeleteUser(user.ID, other.ID, 0); !errors.As(err, &forbidden) {
+		t.Fatalf("DeleteUser(other user) error = %v, want ForbiddenError", err)
+	}
+	if err := s.DeleteUser(seededAdminID, other.ID, 0); err != nil {
+		t.Fatalf("DeleteUser(as admin) error = %v", err)
+	}
+}
+
+func TestPatchUserOtherUsersEmail(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestUserService(t, db, &recordingMailer{})
+	user := createTestUser(t, s, "user@example.com")
+
+	patch := []byte(`{"email": "attacker@example.com"}`)
+	var forbidden *ForbiddenError
+	if _, err := s.PatchUser(user.ID, seededAdminID, patch, 0); !errors.As(err, &forbidden) {
+		t.Fatalf("PatchUser(admin) error = %v, want ForbiddenError", err)
+	}
+	admin, err := s.GetUserByID(seededAdminID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Email == "attacker@example.com" {
+		t.Fatal("admin email changed")
+	}
+
+	if _, err := s.PatchUser(user.ID, user.ID, []byte(`{"email": "user2@example.com"}`), 0); err != nil {
+		t.Fatalf("PatchUser(self) error = %v", err)
+	}
+}
+
+func TestPasswordChangesRevokeAPITokens(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestUserService(t, db, &recordingMailer{})
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	tokens := NewAPITokenService(apiTokenRepo, repository.NewUserRepository(db), repository.NewTxManager(db))
+
+	tests := []struct {
+		name   string
+		change func(user *models.User) error
+	}{
+		{name: "change", change: func(user *models.User) error {
+			_, err := s.ChangePassword(user.ID, &models.ChangePasswordRequest{
+				CurrentPassword: "password123", NewPassword: "password456"}, ClientInfo{IP: "127.0.0.1"})
+			return err
+		}},
+		{name: "forced reset", change: func(user *models.User) error {
+			_, err := s.ForcePasswordReset(seededAdminID, user.ID)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createTestUser(t, s, tt.name+"@example.com")
+			_, err := tokens.CreateToken(user.ID, &models.CreateAPITokenRequest{Name: "ci", Scopes: []string{"pages:read"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.change(user); err != nil {
+				t.Fatal(err)
+			}
+			left, err := apiTokenRepo.GetByUser(user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(left) != 0 {
+				t.Fatalf("%d API tokens left after password %s", len(left), tt.name)
+			}
+		})
+	}
+}
+
+func TestRequestPasswordResetThrottled(t *testing.T) {
+	db := newTestDB(t)
+	mail := &recordingMailer{}
+	s := newTestUserService(t, db, mail)
+	createTestUser(t, s, "user@example.com")
+
+	tests := []struct {
+		name   string
+		email  string
+		client ClientInfo
+	}{
+		{name: "known email", email: "user@example.com", client: ClientInfo{IP: "192.0.2.1"}},
+		{name: "unknown email", email: "nobody@example.com", client: ClientInfo{IP: "192.0.2.2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			for i := 0; i < s.throttle.options.MaxFailures && err == nil; i++ {
+				// Wait out the backoff, so only the lockout stops the requests
+				time.Sleep(2 * s.throttle.options.BackoffMax)
+				err = s.RequestPasswordReset(tt.email, tt.client)
+			}
+			if err != nil {
+				t.Fatalf("RequestPasswordReset() error = %v before the limit", err)
+			}
+
+			var throttled *ThrottledError
+			err = s.RequestPasswordReset(tt.email, ClientInfo{IP: "198.51.100.1"})
+			if !errors.As(err, &throttled) || !throttled.Locked {
+				t.Fatalf("RequestPasswordReset() error = %v, want a lockout of the email", err)
+			}
+		})
+	}
+	if len(mail.sent) != s.throttle.options.MaxFailures {
+		t.Errorf("%d reset emails sent, want %d", len(mail.sent), s.throttle.options.MaxFailures)
+	}
+
+	// The counters are apart from logins
+	if _, err := s.Login(&models.LoginRequest{Email: "user@example.com", Password: "password123"},
+		ClientInfo{IP: "192.0.2.1"}); err != nil {
+		t.Fatalf("Login() error = %v after reset requests", err)
+	}
+}
@@ -1,25 +1,44 @@
 package service
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/xeodocs/xeodocs-dash-api/internal/models"
 	"github.com/xeodocs/xeodocs-dash-api/internal/repository"
+	"github.com/xeodocs/xeodocs-dash-api/internal/siteconfig"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
 )
 
 type WebsiteService struct {
-	websiteRepo *repository.WebsiteRepository
+	websiteRepo  *repository.WebsiteRepository
+	redirectRepo *repository.RedirectRepository
+	txManager    *repository.TxManager
+	trashService *TrashService
 }
 
-func NewWebsiteService(websiteRepo *repository.WebsiteRepository) *WebsiteService {
-	return &WebsiteService{websiteRepo: websiteRepo}
+func NewWebsiteService(websiteRepo *repository.WebsiteRepository, redirectRepo *repository.RedirectRepository,
+	txManager *repository.TxManager, trashService *TrashService) *WebsiteService {
+	return &WebsiteService{
+		websiteRepo:  websiteRepo,
+		redirectRepo: redirectRepo,
+		txManager:    txManager,
+		trashService: trashService,
+	}
 }
 
 func (s *WebsiteService) CreateWebsite(req *models.CreateWebsiteRequest) (*models.Website, error) {
 	// Check if website with same name or slug already exists
 	existingBySlug, _ := s.websiteRepo.GetBySlug(req.Slug)
 	if existingBySlug != nil {
-		return nil, fmt.Errorf("website with slug %s already exists", req.Slug)
+		return nil, &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("website with slug %s already exists", req.Slug)}
+	}
+
+	config, err := parseWebsiteConfig(req.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	website := &models.Website{
@@ -32,11 +51,17 @@ func (s *WebsiteService) CreateWebsite(req *models.CreateWebsiteRequest) (*model
 		GitRepoName:   req.GitRepoName,
 		GitRepoBranch: req.GitRepoBranch,
 		GitAPIToken:   req.GitAPIToken,
-		Config:        req.Config,
+		Config:        *config,
 		LanguageCode:  req.LanguageCode,
 	}
 
-	err := s.websiteRepo.Create(website)
+	err = s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.websiteRepo.WithTx(tx).Create(website); err != nil {
+			return err
+		}
+		// The slug is live now, so it can no longer redirect elsewhere
+		return s.redirectRepo.WithTx(tx).DeleteBySlug(models.RedirectTypeWebsite, website.Slug)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create website: %w", err)
 	}
@@ -48,30 +73,41 @@ func (s *WebsiteService) GetWebsiteByID(id int) (*models.Website, error) {
 	return s.websiteRepo.GetByID(id)
 }
 
+// GetWebsiteBySlug looks up a website by its current slug. Old slugs with a
+// redirect yield a *MovedError pointing to the website's new slug.
 func (s *WebsiteService) GetWebsiteBySlug(slug string) (*models.Website, error) {
-	return s.websiteRepo.GetBySlug(slug)
+	website, err := s.websiteRepo.GetBySlug(slug)
+	if err != nil {
+		redirect, redirectErr := s.redirectRepo.GetBySlug(models.RedirectTypeWebsite, slug)
+		if redirectErr == nil && redirect.TargetSlug != "" {
+			return nil, &MovedError{Redirect: redirect}
+		}
+		return nil, err
+	}
+	return website, nil
 }
 
 func (s *WebsiteService) GetAllWebsites() ([]*models.Website, error) {
 	return s.websiteRepo.GetAll()
 }
 
-func (s *WebsiteService) UpdateWebsite(id int, req *models.UpdateWebsiteRequest) (*models.Website, error) {
+// UpdateWebsite applies the provided fields to a website. A non-zero
+// expectedVersion makes the update conditional on the website's version.
+func (s *WebsiteService) UpdateWebsite(id int, req *models.UpdateWebsiteRequest, expectedVersion int) (*models.Website, error) {
 	website, err := s.websiteRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion("website", website.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+	oldSlug := website.Slug
 
 	// Update fields if provided
 	if req.Name != "" {
 		website.Name = req.Name
 	}
 	if req.Slug != "" {
-		// Check if new slug is already taken by another website
-		existingWebsite, _ := s.websiteRepo.GetBySlug(req.Slug)
-		if existingWebsite != nil && existingWebsite.ID != id {
-			return nil, fmt.Errorf("slug %s is already taken", req.Slug)
-		}
 		website.Slug = req.Slug
 	}
 	if req.Description != "" {
@@ -95,21 +131,216 @@ func (s *WebsiteService) UpdateWebsite(id int, req *models.UpdateWebsiteRequest)
 	if req.GitAPIToken != "" {
 		website.GitAPIToken = req.GitAPIToken
 	}
-	if req.Config != "" {
-		website.Config = req.Config
+	if len(req.Config) > 0 {
+		config, err := parseWebsiteConfig(req.Config)
+		if err != nil {
+			return nil, err
+		}
+		website.Config = *config
 	}
 	if req.LanguageCode != "" {
 		website.LanguageCode = req.LanguageCode
 	}
 
-	err = s.websiteRepo.Update(id, website)
+	return s.saveUpdatedWebsite(website, oldSlug)
+}
+
+// PatchWebsite applies a JSON Merge Patch (RFC 7396) to a website. The patch
+// targets the website's fields as in CreateWebsiteRequest; null clears a
+// field, and the config is merged member by member. The result is validated
+// like a new website. A non-zero expectedVersion makes the update
+// conditional on the website's version.
+func (s *WebsiteService) PatchWebsite(id int, patch []byte, expectedVersion int) (*models.Website, error) {
+	website, err := s.websiteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("website", website.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	config, err := json.Marshal(website.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	doc := &models.CreateWebsiteRequest{
+		Name:          website.Name,
+		Slug:          website.Slug,
+		Description:   website.Description,
+		Slogan:        website.Slogan,
+		Domain:        website.Domain,
+		GitRepoOwner:  website.GitRepoOwner,
+		GitRepoName:   website.GitRepoName,
+		GitRepoBranch: website.GitRepoBranch,
+		GitAPIToken:   website.GitAPIToken,
+		Config:        config,
+		LanguageCode:  website.LanguageCode,
+	}
+	if err := applyMergePatch(doc, patch); err != nil {
+		return nil, err
+	}
+	parsedConfig, err := parseWebsiteConfig(doc.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	oldSlug := website.Slug
+	website.Name = doc.Name
+	website.Slug = doc.Slug
+	website.Description = doc.Description
+	website.Slogan = doc.Slogan
+	website.Domain = doc.Domain
+	website.GitRepoOwner = doc.GitRepoOwner
+	website.GitRepoName = doc.GitRepoName
+	website.GitRepoBranch = doc.GitRepoBranch
+	website.GitAPIToken = doc.GitAPIToken
+	website.Config = *parsedConfig
+	website.LanguageCode = doc.LanguageCode
+
+	return s.saveUpdatedWebsite(website, oldSlug)
+}
+
+// saveUpdatedWebsite stores a modified website, recording a redirect from
+// its old slug when the slug changed.
+func (s *WebsiteService) saveUpdatedWebsite(website *models.Website, oldSlug string) (*models.Website, error) {
+	if website.Slug != oldSlug {
+		// Check if new slug is already taken by another website
+		existingWebsite, _ := s.websiteRepo.GetBySlug(website.Slug)
+		if existingWebsite != nil && existingWebsite.ID != website.ID {
+			return nil, &ConflictError{Code: CodeSlugTaken, Message: fmt.Sprintf("slug %s is already taken", website.Slug)}
+		}
+	}
+
+	err := s.txManager.WithTx(func(tx *sql.Tx) error {
+		if err := s.websiteRepo.WithTx(tx).Update(website.ID, website); err != nil {
+			return err
+		}
+		if website.Slug == oldSlug {
+			return nil
+		}
+		return recordSlugRename(s.redirectRepo.WithTx(tx), models.RedirectTypeWebsite, website.ID, website.ID, oldSlug, website.Slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return website, nil
+}
+
+// DeleteWebsite moves a website and its pages to the trash, or deletes it
+// for good with every page, asset, link, tag and redirect when
+// req.Permanent is set. It reports what is deleted; a dry run only reports
+// it, with the confirmation token that a deletion must pass back. A
+// non-zero expectedVersion makes the deletion conditional on the website's
+// version.
+func (s *WebsiteService) DeleteWebsite(id, expectedVersion int, req *models.DeleteWebsiteRequest) (*models.WebsiteDeletion, error) {
+	if !req.DryRun && req.ConfirmationToken == "" {
+		return nil, invalidField("confirmationToken", "required", "is required; get one with dryRun=true")
+	}
+
+	var summary *models.WebsiteDeletion
+	var blobs []string
+	err := s.txManager.WithTx(func(tx *sql.Tx) error {
+		websiteRepo := s.websiteRepo.WithTx(tx)
+		website, err := websiteRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("website", website.Version, expectedVersion); err != nil {
+			return err
+		}
+
+		summary, err = websiteRepo.GetDeletionSummary(id)
+		if err != nil {
+			return err
+		}
+		summary.Name = website.Name
+		summary.Slug = website.Slug
+		summary.DryRun = req.DryRun
+		summary.Permanent = req.Permanent
+		summary.ConfirmationToken = deletionToken(website, summary)
+		if !req.Permanent {
+			// The trash only hides the website and its pages
+			kept := summary.WebsiteContent
+			summary.Kept = &kept
+			summary.WebsiteContent = models.WebsiteContent{}
+		}
+		if req.DryRun {
+			return nil
+		}
+
+		// The token pins down what the caller saw in the dry run
+		if req.ConfirmationToken != summary.ConfirmationToken {
+			return &ConflictError{Code: CodeConfirmationMismatch,
+				Message: "the confirmation token does not match; the website changed since the dry run or the token is for another deletion"}
+		}
+		if req.Permanent {
+			blobs, err = s.trashService.purgeWebsite(tx, id)
+			return err
+		}
+		// Redirects stay, so a restored website keeps them
+		return websiteRepo.Trash(id, time.Now())
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	s.trashService.deleteBlobs(blobs)
+	return summary, nil
+}
+
+// deletionToken derives the confirmation token of a website deletion from
+// what it deletes, so any change to the website or its pages, or asking
+// for the other kind of deletion, invalidates it.
+func deletionToken(website *models.Website, summary *models.WebsiteDeletion) string {
+	return hashToken(fmt.Sprintf("website-deletion:%d:%d:%t:%d:%d:%d:%d:%d:%d:%d", website.ID, website.Version,
+		summary.Permanent, summary.Pages, summary.TrashedPages, summary.PageVersions,
+		summary.Assets, summary.Links, summary.Redirects, summary.Tags))[:32]
+}
+
+// GetConfigSchema returns the JSON Schema of the website config.
+func (s *WebsiteService) GetConfigSchema() map[string]interface{} {
+	return siteconfig.Schema()
+}
+
+// PatchWebsiteConfig applies a JSON Merge Patch (RFC 7396) to the website
+// config and validates the result like a full replacement. A non-zero
+// expectedVersion makes the update conditional on the website's version.
+func (s *WebsiteService) PatchWebsiteConfig(id int, patch []byte, expectedVersion int) (*models.Website, error) {
+	website, err := s.websiteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("website", website.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	current, err := json.Marshal(website.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	merged, err := utils.MergePatch(current, patch)
+	if err != nil {
+		return nil, invalidRequest(err.Error())
+	}
+
+	config, err := parseWebsiteConfig(merged)
+	if err != nil {
+		return nil, err
+	}
+	website.Config = *config
+
+	if err := s.websiteRepo.Update(id, website); err != nil {
+		return nil, err
+	}
 	return website, nil
 }
 
-func (s *WebsiteService) DeleteWebsite(id int) error {
-	return s.websiteRepo.Delete(id)
+// parseWebsiteConfig validates raw config JSON against the config schema.
+func parseWebsiteConfig(raw []byte) (*models.WebsiteConfig, error) {
+	config, fieldErrors := siteconfig.Parse(raw)
+	if len(fieldErrors) > 0 {
+		return nil, &ValidationError{Message: "invalid website config", Fields: fieldErrors}
+	}
+	return config, nil
 }
@@ -0,0 +1,236 @@
+// Package settings defines the runtime system settings: their keys, types,
+// defaults, bounds and the environment variables that override them.
+//
+// The value of a setting comes from, in order of precedence:
+//
+//  1. its environment variable, which locks the setting;
+//  2. the system_config table, written through the settings API;
+//  3. its default.
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Type is the type of a setting's value.
+type Type string
+
+const (
+	// Duration values are strings such as "30m" or "24h"
+	Duration Type = "duration"
+	Integer  Type = "integer"
+	Boolean  Type = "boolean"
+	String   Type = "string"
+	// Enum values are strings from a fixed list of options
+	Enum Type = "enum"
+)
+
+// Setting keys
+const (
+	SessionIdleTimeout       = "session.idleTimeout"
+	SessionMaxLifetime       = "session.maxLifetime"
+	PasswordMinLength        = "password.minLength"
+	PasswordRequireDigit     = "password.requireDigit"
+	PasswordRequireMixedCase = "password.requireMixedCase"
+	WorkflowDefaultStatus    = "workflow.defaultPageStatus"
+	MaintenanceEnabled       = "maintenance.enabled"
+	MaintenanceMessage       = "maintenance.message"
+	MaintenanceRetryAfter    = "maintenance.retryAfter"
+	TrashRetention           = "trash.retention"
+)
+
+// Definition describes a setting. Values are time.Duration, int, bool or
+// string, after the Type. Min and Max bound durations and integers,
+// MaxLength bounds strings.
+type Definition struct {
+	Key         string
+	Type        Type
+	Env         string
+	Default     interface{}
+	Min, Max    interface{}
+	MaxLength   int
+	Options     []string
+	Description string
+}
+
+// Definitions lists every setting, in the order the API shows them.
+var Definitions = []*Definition{
+	{
+		Key: SessionIdleTimeout, Type: Duration, Env: "SESSION_IDLE_TIMEOUT",
+		Default: 24 * time.Hour, Min: 5 * time.Minute, Max: 30 * 24 * time.Hour,
+		Description: "Session lifetime without activity, renewed on use",
+	},
+	{
+		Key: SessionMaxLifetime, Type: Duration, Env: "SESSION_MAX_LIFETIME",
+		Default: 7 * 24 * time.Hour, Min: time.Hour, Max: 365 * 24 * time.Hour,
+		Description: "Maximum session lifetime after login",
+	},
+	{
+		Key: PasswordMinLength, Type: Integer, Env: "PASSWORD_MIN_LENGTH",
+		Default: 6, Min: 6, Max: 128,
+		Description: "Minimum length of new passwords",
+	},
+	{
+		Key: PasswordRequireDigit, Type: Boolean, Env: "PASSWORD_REQUIRE_DIGIT",
+		Default:     false,
+		Description: "New passwords must contain a digit",
+	},
+	{
+		Key: PasswordRequireMixedCase, Type: Boolean, Env: "PASSWORD_REQUIRE_MIXED_CASE",
+		Default:     false,
+		Description: "New passwords must contain upper and lower case letters",
+	},
+	{
+		Key: WorkflowDefaultStatus, Type: Enum, Env: "DEFAULT_PAGE_STATUS",
+		Default: "draft", Options: []string{"draft", "translating", "translated", "ignored", "published"},
+		Description: "Status of new pages created without one, unless the website config sets workflow.defaultStatus",
+	},
+	{
+		Key: MaintenanceEnabled, Type: Boolean, Env: "MAINTENANCE_MODE",
+		Default:     false,
+		Description: "Read-only mode: writes by non-admins answer 503 and background jobs pause",
+	},
+	{
+		Key: MaintenanceMessage, Type: String, Env: "MAINTENANCE_MESSAGE",
+		Default: "", MaxLength: 500,
+		Description: "Message returned to clients refused during maintenance",
+	},
+	{
+		Key: MaintenanceRetryAfter, Type: Duration, Env: "MAINTENANCE_RETRY_AFTER",
+		Default: 5 * time.Minute, Min: time.Minute, Max: 24 * time.Hour,
+		Description: "Expected end of maintenance, sent as Retry-After",
+	},
+	{
+		Key: TrashRetention, Type: Duration, Env: "TRASH_RETENTION",
+		Default: 30 * 24 * time.Hour, Min: time.Hour, Max: 365 * 24 * time.Hour,
+		Description: "How long deleted websites, pages and users stay restorable before they are purged",
+	},
+}
+
+// Lookup returns the definition of a key.
+func Lookup(key string) (*Definition, bool) {
+	for _, def := range Definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+// InvalidError reports a value that does not fit a setting. Code is a
+// validation code such as "type", "min", "max" or "oneof".
+type InvalidError struct {
+	Code    string
+	Message string
+}
+
+func (e *InvalidError) Error() string {
+	return e.Message
+}
+
+// Decode reads a value from its JSON form, as sent to the API and stored
+// in system_config, and checks it.
+func (d *Definition) Decode(raw json.RawMessage) (interface{}, error) {
+	var value interface{}
+	switch d.Type {
+	case Duration:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return nil, &InvalidError{Code: "type", Message: "must be a duration string such as \"30m\""}
+		}
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, &InvalidError{Code: "type", Message: "must be a duration string such as \"30m\""}
+		}
+		value = duration
+	case Integer:
+		var n int
+		if json.Unmarshal(raw, &n) != nil {
+			return nil, &InvalidError{Code: "type", Message: "must be an integer"}
+		}
+		value = n
+	case Boolean:
+		var b bool
+		if json.Unmarshal(raw, &b) != nil {
+			return nil, &InvalidError{Code: "type", Message: "must be a boolean"}
+		}
+		value = b
+	default:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return nil, &InvalidError{Code: "type", Message: "must be a string"}
+		}
+		value = s
+	}
+	return value, d.check(value)
+}
+
+// Parse reads a value from its environment variable form, e.g. "24h",
+// "12" or "true", and checks it.
+func (d *Definition) Parse(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	var value interface{}
+	var err error
+	switch d.Type {
+	case Duration:
+		value, err = time.ParseDuration(s)
+	case Integer:
+		value, err = strconv.Atoi(s)
+	case Boolean:
+		value, err = strconv.ParseBool(s)
+	default:
+		value = s
+	}
+	if err != nil {
+		return nil, &InvalidError{Code: "type", Message: fmt.Sprintf("%q is not a valid %s", s, d.Type)}
+	}
+	return value, d.check(value)
+}
+
+// Encode returns the JSON form of a value: durations become strings such
+// as "24h0m0s", other values stay as they are.
+func (d *Definition) Encode(value interface{}) interface{} {
+	if duration, ok := value.(time.Duration); ok {
+		return duration.String()
+	}
+	return value
+}
+
+// check enforces the bounds and options of the setting.
+func (d *Definition) check(value interface{}) error {
+	switch v := value.(type) {
+	case time.Duration:
+		if v < d.Min.(time.Duration) {
+			return &InvalidError{Code: "min", Message: "must be at least " + d.Min.(time.Duration).String()}
+		}
+		if v > d.Max.(time.Duration) {
+			return &InvalidError{Code: "max", Message: "must be at most " + d.Max.(time.Duration).String()}
+		}
+	case int:
+		if v < d.Min.(int) {
+			return &InvalidError{Code: "min", Message: fmt.Sprintf("must be at least %d", d.Min)}
+		}
+		if v > d.Max.(int) {
+			return &InvalidError{Code: "max", Message: fmt.Sprintf("must be at most %d", d.Max)}
+		}
+	case string:
+		if d.Type == String {
+			if utf8.RuneCountInString(v) > d.MaxLength {
+				return &InvalidError{Code: "max", Message: fmt.Sprintf("must be at most %d characters long", d.MaxLength)}
+			}
+			return nil
+		}
+		for _, option := range d.Options {
+			if v == option {
+				return nil
+			}
+		}
+		return &InvalidError{Code: "oneof", Message: "must be one of: " + strings.Join(d.Options, ", ")}
+	}
+	return nil
+}
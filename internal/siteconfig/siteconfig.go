@@ -0,0 +1,267 @@
+// Package siteconfig parses, validates and describes the typed website
+// configuration (models.WebsiteConfig).
+package siteconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/xeodocs/xeodocs-dash-api/internal/models"
+	"github.com/xeodocs/xeodocs-dash-api/pkg/utils"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(utils.JSONFieldName)
+	return v
+}
+
+// Default returns the configuration of a website created without one.
+func Default() *models.WebsiteConfig {
+	return &models.WebsiteConfig{Version: models.WebsiteConfigVersion}
+}
+
+// Parse decodes and validates raw config JSON. Unknown keys, mistyped
+// values and rule violations are reported as field errors with JSON paths
+// such as "config.theme.primaryColor". Empty input yields the default
+// config.
+func Parse(raw []byte) (*models.WebsiteConfig, []models.FieldError) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return Default(), nil
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, []models.FieldError{{Field: "config", Code: "invalid_json", Message: "must be valid JSON"}}
+	}
+	if _, ok := generic.(map[string]interface{}); !ok {
+		return nil, []models.FieldError{{Field: "config", Code: "type", Message: "must be a JSON object"}}
+	}
+
+	var fieldErrors []models.FieldError
+	for _, path := range utils.UnknownFields(generic, reflect.TypeOf(models.WebsiteConfig{}), "config") {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: path, Code: "unknown_field", Message: "unknown field"})
+	}
+
+	cfg := &models.WebsiteConfig{}
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return nil, []models.FieldError{{Field: "config", Code: "invalid", Message: err.Error()}}
+		}
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   utils.JSONPath("config." + typeErr.Field),
+			Code:    "type",
+			Message: "must be " + utils.JSONTypeName(typeErr.Type),
+		})
+		return nil, fieldErrors
+	}
+
+	fieldErrors = append(fieldErrors, Validate(cfg)...)
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+	return cfg, nil
+}
+
+// Validate checks a decoded config against its rules.
+func Validate(cfg *models.WebsiteConfig) []models.FieldError {
+	if cfg.Version == 0 {
+		cfg.Version = models.WebsiteConfigVersion
+	}
+
+	var fieldErrors []models.FieldError
+	if err := validate.Struct(cfg); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return []models.FieldError{{Field: "config", Code: "invalid", Message: err.Error()}}
+		}
+		for _, e := range validationErrors {
+			field := "config" + strings.TrimPrefix(e.Namespace(), "WebsiteConfig")
+			fieldErrors = append(fieldErrors, models.FieldError{Field: field, Code: e.Tag(), Message: utils.ValidationMessage(e)})
+		}
+	}
+
+	if locales := cfg.Locales; locales != nil && len(locales.Available) > 0 {
+		found := false
+		for _, code := range locales.Available {
+			found = found || code == locales.Default
+		}
+		if !found {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "config.locales.default",
+				Code:    "oneof",
+				Message: "must be one of the available locales",
+			})
+		}
+	}
+
+	return fieldErrors
+}
+
+// Schema returns a JSON Schema (draft 2020-12) describing WebsiteConfig,
+// derived from its json, validate and description struct tags.
+func Schema() map[string]interface{} {
+	b := &schemaBuilder{defs: make(map[string]interface{})}
+	schema := b.structSchema(reflect.TypeOf(models.WebsiteConfig{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Website configuration"
+	schema["description"] = fmt.Sprintf("Website configuration, schema version %d", models.WebsiteConfigVersion)
+	if len(b.defs) > 0 {
+		schema["$defs"] = b.defs
+	}
+	return schema
+}
+
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		// Named structs live in $defs so recursive types terminate
+		if _, ok := b.defs[t.Name()]; !ok {
+			b.defs[t.Name()] = nil
+			b.defs[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.typeSchema(t.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := utils.JSONFieldName(field)
+		if name == "" {
+			continue
+		}
+
+		schema := b.typeSchema(field.Type)
+		fieldRules, itemRules := splitRules(field.Tag.Get("validate"))
+		if applyRules(schema, field.Type, fieldRules) {
+			required = append(required, name)
+		}
+		if len(itemRules) > 0 {
+			elem := field.Type
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			key := "items"
+			if elem.Kind() == reflect.Map {
+				key = "additionalProperties"
+			}
+			if itemSchema, ok := schema[key].(map[string]interface{}); ok {
+				applyRules(itemSchema, elem.Elem(), itemRules)
+			}
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+		properties[name] = schema
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// splitRules separates the rules of a field from the rules of its elements
+// (those after "dive").
+func splitRules(tag string) ([]string, []string) {
+	if tag == "" {
+		return nil, nil
+	}
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		if rule == "dive" {
+			return rules[:i], rules[i+1:]
+		}
+	}
+	return rules, nil
+}
+
+// applyRules maps validate rules onto schema keywords and reports whether
+// the field is required.
+func applyRules(schema map[string]interface{}, t reflect.Type, rules []string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	collection := t.Kind() == reflect.Slice || t.Kind() == reflect.Map
+
+	required := false
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			var values []interface{}
+			for _, value := range strings.Fields(param) {
+				if n, err := strconv.Atoi(value); err == nil && t.Kind() == reflect.Int {
+					values = append(values, n)
+				} else {
+					values = append(values, value)
+				}
+			}
+			schema["enum"] = values
+		case "len", "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			lower, upper := "minLength", "maxLength"
+			if collection {
+				lower, upper = "minItems", "maxItems"
+			}
+			if name != "max" {
+				schema[lower] = n
+			}
+			if name != "min" {
+				schema[upper] = n
+			}
+		case "hexcolor":
+			schema["pattern"] = "^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$"
+		case "url":
+			schema["format"] = "uri"
+		case "startswith":
+			schema["pattern"] = "^" + regexp.QuoteMeta(param)
+		}
+	}
+	return required
+}
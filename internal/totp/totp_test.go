@@ -0,0 +1,102 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238, Appendix B, in base32.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// TestCodeRFC6238 checks the SHA-1 test vectors of RFC 6238, Appendix B.
+// The RFC lists 8-digit codes; 6-digit codes are their last six digits.
+func TestCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d) error = %v", tt.unix, err)
+		}
+		if want := tt.want[len(tt.want)-Digits:]; got != want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestCodeLowercaseSecret(t *testing.T) {
+	upper, _ := Code(rfcSecret, 1)
+	lower, err := Code(strings.ToLower(rfcSecret), 1)
+	if err != nil || lower != upper {
+		t.Errorf("Code(lowercase) = %s, %v, want %s", lower, err, upper)
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code() accepted an invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	code := func(s int64) string {
+		c, err := Code(rfcSecret, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		valid    bool
+	}{
+		{name: "current step", code: code(step), wantStep: step, valid: true},
+		{name: "previous step within skew", code: code(step - 1), wantStep: step - 1, valid: true},
+		{name: "next step within skew", code: code(step + 1), wantStep: step + 1, valid: true},
+		{name: "surrounding spaces", code: " " + code(step) + " ", wantStep: step, valid: true},
+		{name: "two steps behind", code: code(step - 2)},
+		{name: "two steps ahead", code: code(step + 2)},
+		{name: "wrong code", code: "000000"},
+		{name: "too short", code: code(step)[:Digits-1]},
+		{name: "too long", code: code(step) + "0"},
+
+		// A used step and the steps before it are refused
+		{name: "replay of current step", code: code(step), lastStep: step},
+		{name: "replay of previous step", code: code(step - 1), lastStep: step - 1},
+		{name: "older than last used step", code: code(step - 1), lastStep: step},
+		{name: "later step after use", code: code(step + 1), lastStep: step, wantStep: step + 1, valid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, tt.code, now, tt.lastStep)
+			if ok != tt.valid || got != tt.wantStep {
+				t.Errorf("Validate() = %d, %t, want %d, %t", got, ok, tt.wantStep, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidSecret(t *testing.T) {
+	if _, ok := Validate("not base32!", "123456", time.Now(), 0); ok {
+		t.Error("Validate() accepted a code for an invalid secret")
+	}
+}
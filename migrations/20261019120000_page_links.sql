@@ -0,0 +1,17 @@
+-- Migration: Page link graph
+
+CREATE TABLE IF NOT EXISTS page_links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    website_id INTEGER NOT NULL,
+    source_page_id INTEGER NOT NULL,
+    target_slug TEXT NOT NULL,
+    link_text TEXT NOT NULL,
+    line_number INTEGER NOT NULL,
+    column_number INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (website_id) REFERENCES websites(id) ON DELETE CASCADE,
+    FOREIGN KEY (source_page_id) REFERENCES pages(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_links_source_page_id ON page_links (source_page_id);
+CREATE INDEX IF NOT EXISTS idx_page_links_website_id_target_slug ON page_links (website_id, target_slug);
@@ -0,0 +1,18 @@
+-- Migration: Slug redirects
+
+CREATE TABLE IF NOT EXISTS slug_redirects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_type TEXT NOT NULL CHECK (resource_type IN ('page', 'website')),
+    website_id INTEGER NOT NULL,
+    old_slug TEXT NOT NULL,
+    target_id INTEGER NOT NULL,
+    status_code INTEGER DEFAULT 301 NOT NULL CHECK (status_code IN (301, 302, 307, 308)),
+    is_manual BOOLEAN DEFAULT FALSE NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (resource_type, old_slug),
+    FOREIGN KEY (website_id) REFERENCES websites(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_slug_redirects_website_id ON slug_redirects (website_id);
+CREATE INDEX IF NOT EXISTS idx_slug_redirects_resource_type_target_id ON slug_redirects (resource_type, target_id);
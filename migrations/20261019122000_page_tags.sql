@@ -0,0 +1,35 @@
+-- Migration: Normalized page tags
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    website_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (website_id, name),
+    FOREIGN KEY (website_id) REFERENCES websites(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS page_tags (
+    page_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    position INTEGER DEFAULT 0 NOT NULL,
+    PRIMARY KEY (page_id, tag_id),
+    FOREIGN KEY (page_id) REFERENCES pages(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_tags_tag_id ON page_tags (tag_id);
+
+-- Move existing tags out of the JSON column
+INSERT OR IGNORE INTO tags (website_id, name)
+SELECT DISTINCT p.website_id, TRIM(j.value)
+FROM pages p, json_each(p.tags) j
+WHERE j.type = 'text' AND TRIM(j.value) <> '';
+
+INSERT OR IGNORE INTO page_tags (page_id, tag_id, position)
+SELECT p.id, t.id, j.key
+FROM pages p, json_each(p.tags) j
+JOIN tags t ON t.website_id = p.website_id AND t.name = TRIM(j.value)
+WHERE j.type = 'text';
+
+ALTER TABLE pages DROP COLUMN tags;
@@ -0,0 +1,5 @@
+-- Migration: Version counters for optimistic concurrency control (ETags)
+
+ALTER TABLE pages ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE websites ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+-- Migration: Require the seeded admin account to change its well-known password
+
+UPDATE users SET must_change_password = TRUE
+WHERE email = 'admin@xeodocs.com'
+  AND password_hash = '$2a$12$Nxa2dwJEPDSlhd6AocP8n.I0wu7tFqGE7/WU1R6bMR2osp9o.UGci';
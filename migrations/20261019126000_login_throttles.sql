@@ -0,0 +1,12 @@
+-- Migration: Failed login counters for brute-force protection
+
+-- One row per throttled key: "account:<email>" or "ip:<address>"
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME,
+    locked_until DATETIME,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_throttles_updated_at ON login_throttles (updated_at);
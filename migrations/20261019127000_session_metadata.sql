@@ -0,0 +1,7 @@
+-- Migration: Client details and activity of sessions
+
+ALTER TABLE user_sessions ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN ip_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN last_seen_at DATETIME;
+
+UPDATE user_sessions SET last_seen_at = created_at WHERE last_seen_at IS NULL;
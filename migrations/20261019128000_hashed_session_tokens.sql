@@ -0,0 +1,12 @@
+-- Migration: Store session tokens as SHA-256 hashes with sliding expiry
+
+-- Raw tokens cannot be hashed in SQL, so existing sessions are ended
+DELETE FROM user_sessions;
+
+ALTER TABLE user_sessions RENAME COLUMN session_token TO token_hash;
+
+-- expires_at slides with activity (idle timeout); absolute_expires_at caps it
+ALTER TABLE user_sessions ADD COLUMN absolute_expires_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+DROP INDEX IF EXISTS idx_user_sessions_session_token;
+CREATE INDEX IF NOT EXISTS idx_user_sessions_token_hash ON user_sessions (token_hash);
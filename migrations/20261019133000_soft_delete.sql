@@ -0,0 +1,99 @@
+-- atlas:txmode none
+
+-- Migration: Soft deletion of websites, pages and users (trash)
+
+-- SQLite cannot drop column UNIQUE constraints, so the tables are rebuilt
+-- with unique indexes that only cover rows outside the trash. Foreign keys
+-- are off so dropping the old tables does not cascade to dependent rows.
+PRAGMA foreign_keys = off;
+
+CREATE TABLE new_websites (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    description TEXT NOT NULL,
+    slogan TEXT NOT NULL,
+    domain TEXT NOT NULL,
+    git_repo_owner TEXT NOT NULL,
+    git_repo_name TEXT NOT NULL,
+    git_repo_branch TEXT NOT NULL,
+    git_api_token TEXT NOT NULL,
+    config TEXT CHECK (json_valid(config)) NOT NULL,
+    language_code TEXT CHECK (LENGTH(language_code) = 2) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at DATETIME
+);
+
+INSERT INTO new_websites (id, name, slug, description, slogan, domain, git_repo_owner, git_repo_name,
+    git_repo_branch, git_api_token, config, language_code, created_at, updated_at, version)
+SELECT id, name, slug, description, slogan, domain, git_repo_owner, git_repo_name,
+    git_repo_branch, git_api_token, config, language_code, created_at, updated_at, version
+FROM websites;
+
+DROP TABLE websites;
+ALTER TABLE new_websites RENAME TO websites;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_websites_name ON websites (name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_websites_slug ON websites (slug) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_websites_deleted_at ON websites (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE new_pages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    website_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    description TEXT NOT NULL,
+    markdown_content TEXT NOT NULL,
+    freeze_status BOOLEAN DEFAULT TRUE,
+    status TEXT NOT NULL CHECK (status IN (
+        'draft', 'translating', 'translated', 'ignored', 'published'
+    )),
+    last_status_change_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    scheduled_publish_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at DATETIME,
+    FOREIGN KEY (website_id) REFERENCES websites(id)
+);
+
+INSERT INTO new_pages (id, website_id, title, slug, description, markdown_content, freeze_status, status,
+    last_status_change_at, scheduled_publish_at, created_at, updated_at, version)
+SELECT id, website_id, title, slug, description, markdown_content, freeze_status, status,
+    last_status_change_at, scheduled_publish_at, created_at, updated_at, version
+FROM pages;
+
+DROP TABLE pages;
+ALTER TABLE new_pages RENAME TO pages;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pages_slug ON pages (slug) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pages_status_freeze_sched_created ON pages (status, freeze_status, scheduled_publish_at, updated_at);
+CREATE INDEX IF NOT EXISTS idx_pages_website_id ON pages (website_id);
+CREATE INDEX IF NOT EXISTS idx_pages_deleted_at ON pages (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE new_users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
+    deleted_at DATETIME
+);
+
+INSERT INTO new_users (id, email, password_hash, name, created_at, updated_at, version, must_change_password)
+SELECT id, email, password_hash, name, created_at, updated_at, version, must_change_password
+FROM users;
+
+DROP TABLE users;
+ALTER TABLE new_users RENAME TO users;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON users (email) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+
+PRAGMA foreign_keys = on;
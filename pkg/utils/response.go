@@ -6,40 +6,59 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
+// ProblemContentType is the media type of error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
 
-func SuccessResponse(c *gin.Context, statusCode int, message string, data interface{}) {
-	c.JSON(statusCode, APIResponse{
-		Success: true,
-		Message: message,
-		Data:    data,
-	})
-}
+// Error codes of failures detected at the HTTP layer. Like the service error
+// codes, they are stable and safe for clients to match on.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeInvalidParameter = "invalid_parameter"
+	CodeUnauthorized     = "unauthorized"
+	CodeRouteNotFound    = "route_not_found"
+	CodeUnsupportedMedia = "unsupported_media_type"
+	CodeCSRFFailed       = "csrf_failed"
+	CodeInternalError    = "internal_error"
+)
 
-func ErrorResponse(c *gin.Context, statusCode int, message string) {
-	c.JSON(statusCode, APIResponse{
-		Success: false,
-		Error:   message,
-	})
-}
+// ProblemResponse aborts the request with an RFC 7807 problem details body.
+// code is a stable, machine-readable identifier of the failure and members
+// adds extension members, such as "errors" with per-field details.
+func ProblemResponse(c *gin.Context, statusCode int, code, detail string, members gin.H) {
+	body := gin.H{
+		"type":     "about:blank",
+		"title":    http.StatusText(statusCode),
+		"status":   statusCode,
+		"code":     code,
+		"instance": c.Request.URL.Path,
+	}
+	if detail != "" {
+		body["detail"] = detail
+	}
+	for key, value := range members {
+		body[key] = value
+	}
 
-func ValidationErrorResponse(c *gin.Context, err error) {
-	ErrorResponse(c, http.StatusBadRequest, err.Error())
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(statusCode, body)
 }
 
-func NotFoundResponse(c *gin.Context, resource string) {
-	ErrorResponse(c, http.StatusNotFound, resource+" not found")
+func BadRequestResponse(c *gin.Context, message string) {
+	ProblemResponse(c, http.StatusBadRequest, CodeBadRequest, message, nil)
 }
 
-func InternalErrorResponse(c *gin.Context, message string) {
-	ErrorResponse(c, http.StatusInternalServerError, message)
+// InvalidParameterResponse reports a malformed path or query parameter.
+func InvalidParameterResponse(c *gin.Context, message string) {
+	ProblemResponse(c, http.StatusBadRequest, CodeInvalidParameter, message, nil)
 }
 
 func UnauthorizedResponse(c *gin.Context, message string) {
-	ErrorResponse(c, http.StatusUnauthorized, message)
+	ProblemResponse(c, http.StatusUnauthorized, CodeUnauthorized, message, nil)
+}
+
+// InternalErrorResponse hides err from the client and attaches it to the
+// request, so the logger records it.
+func InternalErrorResponse(c *gin.Context, err error) {
+	c.Error(err)
+	ProblemResponse(c, http.StatusInternalServerError, CodeInternalError, "internal server error", nil)
 }
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// JSONFieldName returns the JSON key of a struct field, or "" if the field
+// is not serialized. Registered as the validator tag name func, it makes
+// validation errors report the names clients send.
+func JSONFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// ValidationMessage describes a failed validation rule in plain words, e.g.
+// "must be at most 64 characters long".
+func ValidationMessage(e validator.FieldError) string {
+	var unit string
+	switch e.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	case reflect.String:
+		unit = " characters long"
+	}
+
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required unless " + strings.ToLower(e.Param()) + " is set"
+	case "excluded_with":
+		return "cannot be combined with " + strings.ToLower(e.Param())
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
+	case "len":
+		if unit == " items" {
+			return fmt.Sprintf("must have exactly %s items", e.Param())
+		}
+		return fmt.Sprintf("must be exactly %s%s", e.Param(), unit)
+	case "max", "lte":
+		if unit == " items" {
+			return fmt.Sprintf("must have at most %s items", e.Param())
+		}
+		return fmt.Sprintf("must be at most %s%s", e.Param(), unit)
+	case "min", "gte":
+		if unit == " items" {
+			return fmt.Sprintf("must have at least %s items", e.Param())
+		}
+		return fmt.Sprintf("must be at least %s%s", e.Param(), unit)
+	case "email":
+		return "must be a valid email address"
+	case "hexcolor":
+		return "must be a hex color such as #0055ff"
+	case "url":
+		return "must be a valid URL"
+	case "bcp47_language_tag":
+		return "must be a language tag such as en or pt-BR"
+	case "timezone":
+		return "must be an IANA time zone such as Europe/Berlin"
+	case "startswith":
+		return fmt.Sprintf("must start with %q", e.Param())
+	default:
+		return fmt.Sprintf("failed the %s rule", e.Tag())
+	}
+}
+
+// JSONTypeName names the JSON type a Go type is decoded from, for messages
+// such as "must be a string".
+func JSONTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return "a string"
+	}
+}
+
+// JSONPath rewrites a dotted decoder path such as "tags.0.name" in the
+// "tags[0].name" form validation errors use.
+func JSONPath(path string) string {
+	var b strings.Builder
+	for i, segment := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(segment); err == nil {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// UnknownFields returns the paths of the object keys in a decoded JSON value
+// that do not exist in the type t, which a plain json.Unmarshal would
+// silently drop. Paths start with path, e.g. "config.theme.colour".
+func UnknownFields(value interface{}, t reflect.Type, path string) []string {
+	var paths []string
+	unknownFields(value, t, path, &paths)
+	return paths
+}
+
+func unknownFields(value interface{}, t reflect.Type, path string, paths *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			if name := JSONFieldName(t.Field(i)); name != "" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for _, key := range sortedKeys(obj) {
+			fieldType, ok := fields[key]
+			if !ok {
+				*paths = append(*paths, path+"."+key)
+				continue
+			}
+			unknownFields(obj[key], fieldType, path+"."+key, paths)
+		}
+	case reflect.Slice:
+		arr, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for i, item := range arr {
+			unknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			unknownFields(obj[key], t.Elem(), path+"."+key, paths)
+		}
+	}
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}